@@ -0,0 +1,653 @@
+/*
+ * This file contains the struct binding, driven by the "form", "json" and "validate" tags.
+ */
+package govel
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxMemory is the "maxMemory" used by Bind to parse multipart forms.
+var DefaultMaxMemory int64 = 32 << 20
+
+// the layouts tried when a string is bound to a time.Time without a "time_format" tag.
+var bindTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	formFileType   = reflect.TypeOf(FormFile{})
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+)
+
+const (
+	validateTagName   = "validate"
+	timeFormatTagName = "time_format"
+
+	// the rule of the message of the values that cannot be converted, see ValidationMessages.
+	bindInvalidRule = "invalid"
+)
+
+// Bind fills the struct pointed by ptr with the request data and validates it.
+//
+// The source is chosen by the Content-Type: a JSON body uses the "json" tags, urlencoded
+// and multipart forms use the "form" tags, and any other request uses the query string
+// with the "form" tags. Nested keys can be written as "address[city]" or "address.city",
+// and arrays as "tags[]" or "items[0][name]".
+//
+// The values are converted to the type of the fields: strings, bools, ints, uints, floats,
+// time.Time (with an optional "time_format" tag), slices, maps, pointers, nested structs,
+// FormFile and *multipart.FileHeader.
+//
+// The rules in the "validate" tags are the same as in Form.Validate:
+//
+//	Email string `form:"email" json:"email" validate:"required|email"`
+//
+// The rules of nested structs are checked at their path, e.g. "address.city", and "items.*.name" for a slice of structs.
+//
+// If the validation fails, a ValidationFailed error with the messages keyed by field is returned.
+func (c *Context) Bind(ptr interface{}) error {
+	value := reflect.ValueOf(ptr)
+
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		panic("ptr is not a pointer to a struct")
+	}
+
+	var validate func(rules Map, onError OnError) (Map, map[string]string)
+	var input map[string]interface{}
+	var tagName string
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		jsonForm, err := c.NewJSONForm()
+
+		if err != nil {
+			return err
+		}
+
+		validate = jsonForm.Validate
+		input = jsonForm.values
+		tagName = "json"
+
+	case mediaType == "multipart/form-data":
+		multipartForm, err := c.NewMultiPartFormDataForm(DefaultMaxMemory)
+
+		if err != nil {
+			return BadRequest(err.Error())
+		}
+
+		validate = multipartForm.Validate
+		input = nestedValues(c.Request.MultipartForm.Value)
+		tagName = "form"
+
+		for key, files := range nestedFiles(c.Request.MultipartForm.File) {
+			input[key] = files
+		}
+
+	default:
+		urlencodedForm, err := c.NewForm()
+
+		if err != nil {
+			return BadRequest(err.Error())
+		}
+
+		validate = urlencodedForm.Validate
+		input = nestedValues(c.Request.Form)
+		tagName = "form"
+	}
+
+	// validate first, so the conversion errors of invalid fields are not reported twice
+	rules := make(Map)
+
+	collectRules(value.Elem().Type(), tagName, rules)
+
+	if len(rules) > 0 {
+		if _, errors := validate(rules, nil); len(errors) > 0 {
+			return ValidationFailed(errors)
+		}
+	}
+
+	errors := make(map[string]string)
+
+	bindStruct(value.Elem(), input, tagName, "", errors)
+
+	if len(errors) > 0 {
+		locale := requestLocale(c.Request)
+
+		for key, rule := range errors {
+			errors[key] = formatMessage(locale, validationMessage(locale, rule), attributeName(locale, key), nil)
+		}
+
+		return ValidationFailed(errors)
+	}
+
+	return nil
+}
+
+// collectRules gets the rules of the "validate" tags of the struct.
+//
+// The rules of nested structs are keyed by their path, e.g. "address.city", and "items.*.name" for the structs of a slice.
+func collectRules(t reflect.Type, tagName string, rules Map) {
+	collectNestedRules(t, tagName, "", rules, make(map[reflect.Type]bool))
+}
+
+func collectNestedRules(t reflect.Type, tagName string, prefix string, rules Map, visiting map[reflect.Type]bool) {
+	// a recursive type has no end, its deeper levels are not validated
+	if visiting[t] {
+		return
+	}
+
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectNestedRules(field.Type, tagName, prefix, rules, visiting)
+			continue
+		}
+
+		name, ok := bindFieldName(field, tagName)
+
+		if !ok {
+			continue
+		}
+
+		if rule := field.Tag.Get(validateTagName); rule != "" {
+			rules[prefix+name] = rule
+		}
+
+		if nested, path := nestedStruct(field.Type, prefix+name); nested != nil {
+			collectNestedRules(nested, tagName, path+".", rules, visiting)
+		}
+	}
+}
+
+// nestedStruct returns the struct type of a field with its own rules and its path, "path.*" for the elements of a slice.
+func nestedStruct(t reflect.Type, path string) (reflect.Type, string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return nestedStruct(t.Elem(), path+".*")
+	}
+
+	if t.Kind() != reflect.Struct || t == timeType || t == formFileType {
+		return nil, ""
+	}
+
+	return t, path
+}
+
+// bindFieldName returns the key of a field, ok is false if the field must be ignored.
+func bindFieldName(field reflect.StructField, tagName string) (name string, ok bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	tag := field.Tag.Get(tagName)
+
+	if tag == "" {
+		// a struct used for JSON and forms usually has both tags
+		for _, other := range []string{"json", "form"} {
+			if tag = field.Tag.Get(other); tag != "" {
+				break
+			}
+		}
+	}
+
+	name = strings.Split(tag, ",")[0]
+
+	if name == "-" {
+		return "", false
+	}
+
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
+
+// bindStruct sets the fields of a struct from a map, the fields that cannot be converted are added to errors with the rule of their message.
+func bindStruct(dst reflect.Value, input map[string]interface{}, tagName string, prefix string, errors map[string]string) {
+	t := dst.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindStruct(dst.Field(i), input, tagName, prefix, errors)
+			continue
+		}
+
+		name, ok := bindFieldName(field, tagName)
+
+		if !ok {
+			continue
+		}
+
+		raw, exists := input[name]
+
+		if !exists {
+			// encoding/json matches the names case-insensitively
+			for key, value := range input {
+				if strings.EqualFold(key, name) {
+					raw, exists = value, true
+					break
+				}
+			}
+		}
+
+		if !exists {
+			continue
+		}
+
+		err := setValue(dst.Field(i), raw, field.Tag.Get(timeFormatTagName), tagName, prefix+name+".", errors)
+
+		if err != nil {
+			errors[prefix+name] = bindInvalidRule
+		}
+	}
+}
+
+// setValue converts raw to the type of dst and sets it.
+func setValue(dst reflect.Value, raw interface{}, timeFormat string, tagName string, prefix string, errors map[string]string) error {
+	if raw == nil {
+		return nil
+	}
+
+	rawValue := reflect.ValueOf(raw)
+
+	if rawValue.Type().AssignableTo(dst.Type()) {
+		dst.Set(rawValue)
+		return nil
+	}
+
+	switch dst.Type() {
+	case timeType:
+		s, ok := scalarString(raw)
+
+		if !ok {
+			return fmt.Errorf("cannot convert %T to time.Time", raw)
+		}
+
+		t, err := parseBindTime(s, timeFormat)
+
+		if err != nil {
+			return err
+		}
+
+		dst.Set(reflect.ValueOf(t))
+		return nil
+
+	case formFileType, fileHeaderType:
+		header, ok := firstValue(raw).(*multipart.FileHeader)
+
+		if !ok {
+			return fmt.Errorf("cannot convert %T to a file", raw)
+		}
+
+		if dst.Type() == fileHeaderType {
+			dst.Set(reflect.ValueOf(header))
+			return nil
+		}
+
+		file, err := header.Open()
+
+		if err != nil {
+			return err
+		}
+
+		dst.Set(reflect.ValueOf(FormFile{File: file, FileHeader: header}))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+
+		return setValue(dst.Elem(), raw, timeFormat, tagName, prefix, errors)
+
+	case reflect.Slice, reflect.Array:
+		items := toSlice(raw)
+
+		if dst.Kind() == reflect.Slice {
+			dst.Set(reflect.MakeSlice(dst.Type(), len(items), len(items)))
+		}
+
+		for i, item := range items {
+			if i >= dst.Len() {
+				break
+			}
+
+			if err := setValue(dst.Index(i), item, timeFormat, tagName, prefix+strconv.Itoa(i)+".", errors); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case reflect.Map:
+		m, ok := raw.(map[string]interface{})
+
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("cannot convert %T to %s", raw, dst.Type())
+		}
+
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+
+		for key, item := range m {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+
+			if err := setValue(elem, item, timeFormat, tagName, prefix+key+".", errors); err != nil {
+				return err
+			}
+
+			dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+
+		return nil
+
+	case reflect.Struct:
+		m, ok := raw.(map[string]interface{})
+
+		if !ok {
+			return fmt.Errorf("cannot convert %T to %s", raw, dst.Type())
+		}
+
+		bindStruct(dst, m, tagName, prefix, errors)
+		return nil
+	}
+
+	// from here on only scalar values can be converted
+	s, ok := scalarString(raw)
+
+	if !ok {
+		return fmt.Errorf("cannot convert %T to %s", raw, dst.Type())
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+
+	case reflect.Bool:
+		b, err := parseBindBool(s)
+
+		if err != nil {
+			return err
+		}
+
+		dst.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if dst.Type() == reflect.TypeOf(time.Duration(0)) {
+			if d, err := time.ParseDuration(s); err == nil {
+				dst.SetInt(int64(d))
+				return nil
+			}
+		}
+
+		n, err := strconv.ParseInt(s, 10, dst.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		dst.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, dst.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		dst.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, dst.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		dst.SetFloat(n)
+
+	default:
+		return fmt.Errorf("cannot bind to %s", dst.Type())
+	}
+
+	return nil
+}
+
+// scalarString returns the string form of a scalar value, a slice with one element counts as that element.
+func scalarString(raw interface{}) (string, bool) {
+	switch value := firstValue(raw).(type) {
+	case string:
+		return strings.TrimSpace(value), true
+
+	case json.Number:
+		return value.String(), true
+
+	case bool:
+		return strconv.FormatBool(value), true
+
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(value), true
+
+	case time.Time:
+		return value.Format(time.RFC3339Nano), true
+	}
+
+	return "", false
+}
+
+// firstValue returns the only element of a slice with one element, or raw.
+func firstValue(raw interface{}) interface{} {
+	items := toSlice(raw)
+
+	if len(items) == 1 {
+		return items[0]
+	}
+
+	return raw
+}
+
+// toSlice returns the elements of a slice, or a slice with raw as its only element.
+func toSlice(raw interface{}) []interface{} {
+	value := reflect.ValueOf(raw)
+
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return []interface{}{raw}
+	}
+
+	items := make([]interface{}, value.Len())
+
+	for i := range items {
+		items[i] = value.Index(i).Interface()
+	}
+
+	return items
+}
+
+// parseBindBool parses a boolean, including the values sent by checkboxes.
+func parseBindBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on", "yes":
+		return true, nil
+
+	case "off", "no", "":
+		return false, nil
+	}
+
+	return strconv.ParseBool(s)
+}
+
+// parseBindTime parses a time with the "time_format" layout or the default layouts.
+func parseBindTime(s string, layout string) (time.Time, error) {
+	if layout != "" {
+		return time.Parse(layout, s)
+	}
+
+	var err error
+
+	for _, layout := range bindTimeLayouts {
+		var t time.Time
+
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+/*
+ * Nested form keys
+ */
+
+// splitFormKey splits "items[0][name]" and "items.0.name" into ["items", "0", "name"].
+//
+// "tags[]" returns ["tags", ""].
+func splitFormKey(key string) []string {
+	var parts []string
+
+	for _, part := range strings.Split(key, "[") {
+		part = strings.TrimSuffix(part, "]")
+
+		if len(parts) == 0 {
+			parts = append(parts, strings.Split(part, ".")...)
+		} else {
+			parts = append(parts, part)
+		}
+	}
+
+	return parts
+}
+
+// nestedValues converts the flat values of a form into nested maps and slices.
+//
+// A key with one value is a string, with more values a []interface{}.
+func nestedValues(values url.Values) map[string]interface{} {
+	return normalizeNested(valuesTree(values)).(map[string]interface{})
+}
+
+// valuesTree converts the flat values of a form into nested maps, the indexes are kept as keys of the maps.
+func valuesTree(values url.Values) map[string]interface{} {
+	tree := make(map[string]interface{})
+
+	// sort the keys so "items[1]" and "items[10]" are always inserted in the same order
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range values[key] {
+			insertNested(tree, splitFormKey(key), value, len(values[key]) > 1)
+		}
+	}
+
+	return tree
+}
+
+// nestedFiles converts the files of a multipart form into nested maps and slices of *multipart.FileHeader.
+func nestedFiles(files map[string][]*multipart.FileHeader) map[string]interface{} {
+	tree := make(map[string]interface{})
+
+	for key, headers := range files {
+		for _, header := range headers {
+			insertNested(tree, splitFormKey(key), header, len(headers) > 1)
+		}
+	}
+
+	return normalizeNested(tree).(map[string]interface{})
+}
+
+// insertNested inserts a value in a tree of maps, "" parts and repeated keys append to a list.
+func insertNested(tree map[string]interface{}, path []string, value interface{}, multiple bool) {
+	key := path[0]
+
+	if len(path) == 1 || (len(path) == 2 && path[1] == "") {
+		if len(path) == 2 || multiple {
+			list, _ := tree[key].([]interface{})
+			tree[key] = append(list, value)
+		} else {
+			tree[key] = value
+		}
+
+		return
+	}
+
+	child, ok := tree[key].(map[string]interface{})
+
+	if !ok {
+		child = make(map[string]interface{})
+		tree[key] = child
+	}
+
+	insertNested(child, path[1:], value, multiple)
+}
+
+// normalizeNested converts the maps whose keys are all indexes to slices.
+func normalizeNested(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		indexes := make([]int, 0, len(v))
+
+		for key, item := range v {
+			v[key] = normalizeNested(item)
+
+			if index, err := strconv.Atoi(key); err == nil && index >= 0 {
+				indexes = append(indexes, index)
+			}
+		}
+
+		if len(v) == 0 || len(indexes) != len(v) {
+			return v
+		}
+
+		sort.Ints(indexes)
+
+		list := make([]interface{}, 0, len(indexes))
+
+		for _, index := range indexes {
+			list = append(list, v[strconv.Itoa(index)])
+		}
+
+		return list
+
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNested(item)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,96 @@
+package govel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type bindTestAddress struct {
+	City string `json:"city" form:"city" validate:"required"`
+}
+
+type bindTestItem struct {
+	Name string `json:"name" form:"name" validate:"required|min:2"`
+}
+
+type bindTestUser struct {
+	Age     int             `json:"age" form:"age"`
+	Address bindTestAddress `json:"address" form:"address"`
+	Items   []bindTestItem  `json:"items" form:"items"`
+}
+
+func bindTestContext(contentType string, body string) *Context {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+
+	return newContext(httptest.NewRecorder(), r)
+}
+
+// bindErrors returns the messages of the ValidationFailed error of Bind.
+func bindErrors(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	var httpError *HTTPError
+
+	if !errors.As(err, &httpError) {
+		t.Fatalf("Bind = %v, want a ValidationFailed error", err)
+	}
+
+	return httpError.Details.(map[string]string)
+}
+
+func TestCollectRules(t *testing.T) {
+	rules := make(Map)
+
+	collectRules(reflect.TypeOf(bindTestUser{}), "json", rules)
+
+	want := Map{"address.city": "required", "items.*.name": "required|min:2"}
+
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %v, want %v", rules, want)
+	}
+}
+
+func TestBindNestedRules(t *testing.T) {
+	var user bindTestUser
+
+	errs := bindErrors(t, bindTestContext("application/json", `{"address":{},"items":[{"name":"ab"},{"name":"x"}]}`).Bind(&user))
+
+	want := map[string]string{"address.city": "address.city is required", "items.1.name": "items.1.name must have at least 2 characters"}
+
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors = %v, want %v", errs, want)
+	}
+
+	user = bindTestUser{}
+
+	if err := bindTestContext("application/x-www-form-urlencoded", "address[city]=Berlin&items[0][name]=ab&age=3").Bind(&user); err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Address.City != "Berlin" || len(user.Items) != 1 || user.Items[0].Name != "ab" || user.Age != 3 {
+		t.Errorf("user = %+v", user)
+	}
+}
+
+func TestBindConversionErrorLocale(t *testing.T) {
+	var user bindTestUser
+
+	c := bindTestContext("application/x-www-form-urlencoded", "address[city]=Berlin&age=x")
+
+	if errs := bindErrors(t, c.Bind(&user)); errs["age"] != "age is not valid" {
+		t.Errorf("errors = %v", errs)
+	}
+
+	c = bindTestContext("application/x-www-form-urlencoded", "address[city]=Berlin&age=x")
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), localeContextKey{}, "es"))
+
+	if errs := bindErrors(t, c.Bind(&user)); errs["age"] != "age no es válido" {
+		t.Errorf("errors = %v", errs)
+	}
+}
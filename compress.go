@@ -0,0 +1,244 @@
+/*
+ * This file contains the gzip/deflate response compression middleware.
+ */
+package govel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompressConfig is the configuration of the Compress middleware.
+type CompressConfig struct {
+	// Level is the compression level, from 1 (best speed) to 9 (best compression).
+	//
+	// 0 uses the default level.
+	Level int
+
+	// MinSize is the minimum size in bytes of a response to be compressed.
+	//
+	// 0 uses 1024 bytes.
+	MinSize int
+
+	// ExcludedContentTypes are the content types that are never compressed.
+	//
+	// A content type ending in "/" excludes the whole type (e.g. "image/").
+	// nil excludes the most common already-compressed content types.
+	ExcludedContentTypes []string
+}
+
+var defaultExcludedContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"font/woff2",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-rar-compressed",
+	"application/x-7z-compressed",
+	"application/pdf",
+	"application/wasm",
+	"text/event-stream",
+}
+
+// Compress returns a middleware that compresses the response with gzip or deflate, depending on the "Accept-Encoding" header.
+func Compress(config CompressConfig) middlewareFunction {
+	if config.Level == 0 {
+		config.Level = gzip.DefaultCompression
+	}
+
+	if config.MinSize == 0 {
+		config.MinSize = 1024
+	}
+
+	if config.ExcludedContentTypes == nil {
+		config.ExcludedContentTypes = defaultExcludedContentTypes
+	}
+
+	// check the level once instead of on every request
+	_, err := gzip.NewWriterLevel(io.Discard, config.Level)
+
+	if err != nil {
+		panic("Compress: " + err.Error())
+	}
+
+	gzipWriters := sync.Pool{New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, config.Level)
+
+		return w
+	}}
+
+	// "deflate" is the zlib format, see RFC 9110 section 8.4.1.2
+	zlibWriters := sync.Pool{New: func() interface{} {
+		w, _ := zlib.NewWriterLevel(io.Discard, config.Level)
+
+		return w
+	}}
+
+	return func(c *Context) int {
+		c.BeforeWrite(func(c *Context) {
+			contentType := c.Headers["Content-Type"]
+
+			if contentType == "" {
+				contentType = http.DetectContentType(c.Buf.Bytes())
+			}
+
+			if c.Headers["Content-Encoding"] != "" || isExcludedContentType(contentType, config.ExcludedContentTypes) {
+				return
+			}
+
+			c.addVary("Accept-Encoding")
+
+			if c.Buf.Len() < config.MinSize || c.Request.Method == http.MethodHead || !bodyAllowedForStatus(c.statusCode) {
+				return
+			}
+
+			compressed := new(bytes.Buffer)
+
+			switch negotiateEncoding(c.GetHeader("Accept-Encoding"), "gzip", "deflate") {
+			case "gzip":
+				w := gzipWriters.Get().(*gzip.Writer)
+				defer gzipWriters.Put(w)
+
+				w.Reset(compressed)
+				w.Write(c.Buf.Bytes())
+				w.Close()
+
+				c.Headers["Content-Encoding"] = "gzip"
+
+			case "deflate":
+				w := zlibWriters.Get().(*zlib.Writer)
+				defer zlibWriters.Put(w)
+
+				w.Reset(compressed)
+				w.Write(c.Buf.Bytes())
+				w.Close()
+
+				c.Headers["Content-Encoding"] = "deflate"
+
+			default:
+				return
+			}
+
+			delete(c.Headers, "Content-Length")
+
+			if contentType != c.Headers["Content-Type"] {
+				// keep the sniffed content type, otherwise it would be sniffed from the compressed body
+				c.ContentType(contentType)
+			}
+
+			c.Buf = compressed
+
+			// a strong ETag of the uncompressed body must not validate the compressed one
+			if etag := c.Headers["ETag"]; strings.HasPrefix(etag, `"`) {
+				c.Headers["ETag"] = strings.TrimSuffix(etag, `"`) + "-" + c.Headers["Content-Encoding"] + `"`
+
+				if etagMatches(c.GetHeader("If-None-Match"), c.Headers["ETag"]) {
+					c.notModified()
+				}
+			}
+		})
+
+		return ContinueRequest
+	}
+}
+
+// isExcludedContentType reports whether contentType matches one of the excluded content types.
+func isExcludedContentType(contentType string, excluded []string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+
+	for _, e := range excluded {
+		if (strings.HasSuffix(e, "/") && strings.HasPrefix(contentType, e)) || contentType == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bodyAllowedForStatus reports whether a response with the given status code can have a body.
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+
+	return true
+}
+
+// negotiateEncoding returns the first of the supported encodings accepted by the "Accept-Encoding" header, or "" if none is.
+func negotiateEncoding(acceptEncoding string, supported ...string) string {
+	best := ""
+	bestQuality := 0.0
+
+	for _, encoding := range supported {
+		quality := encodingQuality(acceptEncoding, encoding)
+
+		if quality > bestQuality {
+			best = encoding
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// encodingQuality returns the q-value of an encoding in an "Accept-Encoding" header.
+func encodingQuality(acceptEncoding string, encoding string) float64 {
+	wildcard := 0.0
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+
+		q := 1.0
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = value
+				}
+			}
+		}
+
+		if name == encoding {
+			return q
+		}
+
+		if name == "*" {
+			wildcard = q
+		}
+	}
+
+	return wildcard
+}
+
+// addVary adds a header name to the "Vary" header.
+func (c *Context) addVary(header string) {
+	vary := c.Headers["Vary"]
+
+	for _, value := range strings.Split(vary, ",") {
+		if strings.EqualFold(strings.TrimSpace(value), header) {
+			return
+		}
+	}
+
+	if vary == "" {
+		c.Headers["Vary"] = header
+	} else {
+		c.Headers["Vary"] = vary + ", " + header
+	}
+}
@@ -0,0 +1,68 @@
+package govel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressWithETag(t *testing.T) {
+	body := strings.Repeat("hello world ", 200)
+
+	chains := map[string]middlewaresFunctions{
+		"ETag before Compress": {ETag(false), Compress(CompressConfig{})},
+		"Compress before ETag": {Compress(CompressConfig{}), ETag(false)},
+	}
+
+	for name, middlewares := range chains {
+		t.Run(name, func(t *testing.T) {
+			route := &routeModel{middlewares: middlewares, action: toRouteFunction(func(c *Context) {
+				c.Buf.WriteString(body)
+			})}
+
+			serve := func(acceptEncoding string, ifNoneMatch string) *httptest.ResponseRecorder {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+				if acceptEncoding != "" {
+					r.Header.Set("Accept-Encoding", acceptEncoding)
+				}
+
+				if ifNoneMatch != "" {
+					r.Header.Set("If-None-Match", ifNoneMatch)
+				}
+
+				w := httptest.NewRecorder()
+				callFunction(route).ServeHTTP(w, r)
+
+				return w
+			}
+
+			gzipped := serve("gzip", "")
+			identity := serve("", "")
+
+			if gzipped.Header().Get("Content-Encoding") != "gzip" {
+				t.Fatalf("Content-Encoding = %q, want gzip", gzipped.Header().Get("Content-Encoding"))
+			}
+
+			gzipTag := gzipped.Header().Get("ETag")
+			identityTag := identity.Header().Get("ETag")
+
+			if gzipTag == "" || identityTag == "" || gzipTag == identityTag {
+				t.Fatalf("ETags of gzip %q and identity %q must be set and differ", gzipTag, identityTag)
+			}
+
+			if w := serve("", gzipTag); w.Code != http.StatusOK || w.Body.String() != body {
+				t.Errorf("identity request with the gzip ETag: status %d, want 200 with the body", w.Code)
+			}
+
+			if w := serve("gzip", gzipTag); w.Code != http.StatusNotModified {
+				t.Errorf("gzip request with the gzip ETag: status %d, want 304", w.Code)
+			}
+
+			if w := serve("", identityTag); w.Code != http.StatusNotModified {
+				t.Errorf("identity request with the identity ETag: status %d, want 304", w.Code)
+			}
+		})
+	}
+}
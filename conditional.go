@@ -0,0 +1,122 @@
+/*
+ * This file contains the ETag middleware and the helpers for conditional GET requests.
+ */
+package govel
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag returns a middleware that sets the "ETag" header from the response body
+// and answers with 304 Not Modified when it matches the "If-None-Match" header.
+//
+// If weak is true a weak validator (W/"...") is generated.
+// A strong validator of a response compressed by Compress gets the encoding as a suffix, e.g. "...-gzip".
+// Only successful GET and HEAD responses without an "ETag" header are handled.
+func ETag(weak bool) middlewareFunction {
+	return func(c *Context) int {
+		c.BeforeWrite(func(c *Context) {
+			if !isConditionalMethod(c.Request.Method) || c.statusCode != http.StatusOK || c.Headers["ETag"] != "" {
+				return
+			}
+
+			sum := sha1.Sum(c.Buf.Bytes())
+			tag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+			if weak {
+				tag = "W/" + tag
+			}
+
+			c.Headers["ETag"] = tag
+
+			if etagMatches(c.GetHeader("If-None-Match"), tag) {
+				c.notModified()
+			}
+		})
+
+		return ContinueRequest
+	}
+}
+
+// LastModified sets the "Last-Modified" header.
+//
+// Call NotModifiedSince afterwards to check if the client already has this version.
+func (c *Context) LastModified(t time.Time) {
+	c.lastModified = t
+	c.Headers["Last-Modified"] = t.UTC().Format(http.TimeFormat)
+}
+
+// NotModifiedSince reports whether the client already has the current version of the resource,
+// based on the "ETag" and "Last-Modified" headers set before the call.
+//
+// If it returns true the response is set to 304 Not Modified, so the action can return immediately:
+//
+//	c.LastModified(post.UpdatedAt)
+//
+//	if c.NotModifiedSince() {
+//		return
+//	}
+func (c *Context) NotModifiedSince() bool {
+	if !isConditionalMethod(c.Request.Method) {
+		return false
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if c.Headers["ETag"] == "" || !etagMatches(ifNoneMatch, c.Headers["ETag"]) {
+			return false
+		}
+
+		c.notModified()
+
+		return true
+	}
+
+	ifModifiedSince, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+
+	if err != nil || c.lastModified.IsZero() {
+		return false
+	}
+
+	// the header has a precision of one second
+	if c.lastModified.Truncate(time.Second).After(ifModifiedSince) {
+		return false
+	}
+
+	c.notModified()
+
+	return true
+}
+
+// notModified turns the response into a 304 Not Modified.
+func (c *Context) notModified() {
+	c.statusCode = http.StatusNotModified
+	c.Buf.Reset()
+
+	delete(c.Headers, "Content-Type")
+	delete(c.Headers, "Content-Length")
+}
+
+// isConditionalMethod reports whether If-None-Match and If-Modified-Since apply to the method.
+func isConditionalMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// etagMatches compares an "If-None-Match" header with an ETag using the weak comparison.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
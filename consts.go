@@ -12,3 +12,18 @@ const (
 	colorRed   = "\033[31m"
 	colorReset = "\033[0m"
 )
+
+const (
+	// frameworkSessionName is the name of the session used by the framework for the flash data and the CSRF token.
+	frameworkSessionName = "govel"
+
+	// flash keys of the validation errors and the old input.
+	errorsFlashKey   = "_errors"
+	oldInputFlashKey = "_old_input"
+
+	// session key of the locale saved by DetectLocale and SetLocale.
+	localeSessionKey = "_locale"
+
+	// session key of the URL saved by StorePreviousURL.
+	previousURLSessionKey = "_previous_url"
+)
@@ -62,7 +62,18 @@ func (c *Context) Status(s int) *Context {
 	return c
 }
 
+// BeforeWrite registers a function that is called after the action, right before the response is written.
+//
+// Middlewares can use it to modify the status code, the headers or Buf.
+// The functions are called in the reverse order of registration.
+// They are not called for streamed responses.
+func (c *Context) BeforeWrite(function func(c *Context)) {
+	c.beforeWrite = append(c.beforeWrite, function)
+}
+
 // SetFormValues sets the values of the struct from a map by the "form" tag.
+//
+// The values are converted to the type of the fields like in Bind.
 func (c *Context) SetFormValues(ptr interface{}, formValues map[string]interface{}) {
 	value := reflect.ValueOf(ptr)
 
@@ -79,15 +90,11 @@ func (c *Context) SetFormValues(ptr interface{}, formValues map[string]interface
 
 		if tag != "" {
 			if fieldValue, ok := formValues[tag]; ok {
+				err := setValue(value.Field(i), fieldValue, field.Tag.Get(timeFormatTagName), "form", tag+".", make(map[string]string))
 
-				fieldValue := reflect.ValueOf(fieldValue)
-
-				if !fieldValue.Type().AssignableTo(field.Type) {
+				if err != nil {
 					panic(fmt.Sprintf("value for field '%s' is not assignable to its type", field.Name))
-
 				}
-
-				value.Field(i).Set(fieldValue.Convert(field.Type))
 			}
 		}
 	}
@@ -0,0 +1,41 @@
+/*
+ * This file contains the CSRF token of the views.
+ */
+package govel
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+const (
+	csrfSessionKey = "_csrf_token"
+
+	// CSRFFieldName is the name of the form field that contains the CSRF token.
+	CSRFFieldName = "_token"
+)
+
+// CSRFToken returns the CSRF token of the session, it is created if needed.
+//
+// Sessions must be configured (keys.sessions in the .yaml file).
+func (c *Context) CSRFToken() string {
+	session, err := c.frameworkSession()
+
+	getErr(err)
+
+	token, _ := session.Get(csrfSessionKey).(string)
+
+	if token == "" {
+		random := make([]byte, 32)
+
+		_, err := rand.Read(random)
+
+		getErr(err)
+
+		token = base64.RawURLEncoding.EncodeToString(random)
+
+		session.Set(csrfSessionKey, token)
+	}
+
+	return token
+}
@@ -0,0 +1,276 @@
+/*
+ * This file contains the database of the "sql" section of the .yaml file and the database validation rules.
+ */
+package govel
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// the "sql" section of the .yaml file.
+	sqlConfig sqlStruct
+
+	database     *sql.DB
+	databaseErr  error
+	databaseOnce sync.Once
+
+	// the verifier of the unique and exists rules, set with SetPresenceVerifier.
+	presenceVerifier PresenceVerifier
+
+	// matches a table or a column, optionally with a schema: "users" or "public.users".
+	identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
+
+// PresenceVerifier counts the records of a store for the unique and exists rules.
+//
+// The default one queries the database of the "sql" section of the .yaml file, see SetPresenceVerifier
+// to use another store.
+type PresenceVerifier interface {
+	// Count returns the number of records of table whose column is value.
+	//
+	// If excludeID is not empty, the record whose idColumn is excludeID is not counted.
+	Count(table string, column string, value string, excludeID string, idColumn string) (int, error)
+}
+
+// SetPresenceVerifier sets the PresenceVerifier of the unique and exists rules.
+func SetPresenceVerifier(verifier PresenceVerifier) {
+	presenceVerifier = verifier
+}
+
+// DB returns the database of the "sql" section of the .yaml file, it is opened the first time it is used.
+//
+// The driver must be imported by the application, e.g. _ "github.com/go-sql-driver/mysql".
+// The data source name is built from host, user, password and database, or set with "dsn".
+func DB() (*sql.DB, error) {
+	databaseOnce.Do(func() {
+		if sqlConfig.Driver == "" {
+			databaseErr = errors.New("govel: the database is not configured, set sql.driver in the .yaml file")
+			return
+		}
+
+		database, databaseErr = sql.Open(sqlConfig.Driver, dataSourceName(sqlConfig))
+	})
+
+	return database, databaseErr
+}
+
+// dataSourceName builds the data source name of the common drivers.
+func dataSourceName(config sqlStruct) string {
+	if config.Dsn != "" {
+		return config.Dsn
+	}
+
+	switch config.Driver {
+	case "postgres", "pgx":
+		dsn := url.URL{Scheme: "postgres", Host: config.Host, Path: "/" + config.Database}
+
+		if config.User != "" {
+			dsn.User = url.UserPassword(config.User, config.Password)
+		}
+
+		return dsn.String()
+
+	case "sqlite", "sqlite3":
+		return config.Database
+	}
+
+	// mysql
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true", config.User, config.Password, config.Host, config.Database)
+}
+
+// sqlPresenceVerifier is the PresenceVerifier of the database of the .yaml file.
+type sqlPresenceVerifier struct{}
+
+func (sqlPresenceVerifier) Count(table string, column string, value string, excludeID string, idColumn string) (int, error) {
+	db, err := DB()
+
+	if err != nil {
+		return 0, err
+	}
+
+	// the identifiers cannot be query parameters, they are checked by the rules
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s", table, column, sqlPlaceholder(1))
+	args := []interface{}{value}
+
+	if excludeID != "" {
+		query += fmt.Sprintf(" AND %s <> %s", idColumn, sqlPlaceholder(2))
+		args = append(args, excludeID)
+	}
+
+	var count int
+
+	err = db.QueryRow(query, args...).Scan(&count)
+
+	return count, err
+}
+
+// sqlPlaceholder returns the n-th query parameter of the configured driver.
+func sqlPlaceholder(n int) string {
+	switch sqlConfig.Driver {
+	case "postgres", "pgx":
+		return "$" + strconv.Itoa(n)
+	}
+
+	return "?"
+}
+
+/*
+ * Validators
+ */
+
+// validatorUnique validates that no record has the value, the parameter is table,column[,excludeID[,idColumn]].
+//
+// column defaults to the key of the field and idColumn to "id".
+func validatorUnique(key string, value string, form formInterface) error {
+	params, err := presenceParams("unique", key, value)
+
+	if err != nil {
+		return err
+	}
+
+	excludeID, idColumn := "", "id"
+
+	if len(params) > 2 {
+		excludeID = params[2]
+	}
+
+	if len(params) > 3 {
+		idColumn = params[3]
+	}
+
+	count, countErr := presenceCount(params[0], params[1], validationString(form), excludeID, idColumn)
+
+	if countErr != nil {
+		return &validationError{Rule: "unavailable", Key: key, Err: countErr}
+	}
+
+	if count > 0 {
+		return &validationError{Rule: "unique", Key: key}
+	}
+
+	return nil
+}
+
+// validatorExists validates that a record has the value, the parameter is table[,column].
+//
+// column defaults to the key of the field.
+func validatorExists(key string, value string, form formInterface) error {
+	params, err := presenceParams("exists", key, value)
+
+	if err != nil {
+		return err
+	}
+
+	count, countErr := presenceCount(params[0], params[1], validationString(form), "", "")
+
+	if countErr != nil {
+		return &validationError{Rule: "unavailable", Key: key, Err: countErr}
+	}
+
+	if count == 0 {
+		return &validationError{Rule: "exists", Key: key}
+	}
+
+	return nil
+}
+
+// presenceParams returns the table, the column and the rest of the parameters of the unique and exists rules.
+//
+// The identifiers of the parameter are checked when the rules are set, see checkPresenceRules. A column that
+// defaults to a key sent by the client, e.g. "prices.*", is checked here and fails the rule if it is not valid.
+func presenceParams(rule string, key string, value string) ([]string, error) {
+	params := splitParams(value)
+
+	if len(params) == 1 {
+		params = append(params, "")
+	}
+
+	if err := checkPresenceParams(rule, params); err != nil {
+		return nil, &validationError{Rule: rule, Key: key, Err: err}
+	}
+
+	if params[1] == "" {
+		// the last part of a nested key, "items.0.email" is "email"
+		params[1] = key[strings.LastIndex(key, ".")+1:]
+
+		if !identifierRegex.MatchString(params[1]) {
+			return nil, &validationError{Rule: rule, Key: key}
+		}
+	}
+
+	return params, nil
+}
+
+// checkPresenceParams checks the table, the column and the idColumn of the parameters of the unique and exists rules.
+func checkPresenceParams(rule string, params []string) error {
+	identifiers := []string{params[0], params[1]}
+
+	if rule == "unique" && len(params) > 3 {
+		identifiers = append(identifiers, params[3])
+	}
+
+	for i, identifier := range identifiers {
+		// the column can be left empty
+		if i == 1 && identifier == "" {
+			continue
+		}
+
+		if !identifierRegex.MatchString(identifier) {
+			return fmt.Errorf("\"%s\" is not a valid table or column for the %s rule", identifier, rule)
+		}
+	}
+
+	return nil
+}
+
+// checkPresenceRules panics if a unique or exists rule has an invalid table or column, so it fails when the rules are set.
+func checkPresenceRules(rules Map) {
+	for _, value := range rules {
+		var fieldRules []string
+
+		switch r := value.(type) {
+		case string:
+			fieldRules = strings.Split(r, "|")
+
+		case []string:
+			fieldRules = r
+		}
+
+		for _, rule := range fieldRules {
+			name, param, hasParam := strings.Cut(rule, ":")
+
+			if !hasParam || (name != "unique" && name != "exists") {
+				continue
+			}
+
+			params := splitParams(param)
+
+			if len(params) == 1 {
+				params = append(params, "")
+			}
+
+			if err := checkPresenceParams(name, params); err != nil {
+				panic(err.Error())
+			}
+		}
+	}
+}
+
+// presenceCount counts the records with the verifier.
+func presenceCount(table string, column string, value string, excludeID string, idColumn string) (int, error) {
+	verifier := presenceVerifier
+
+	if verifier == nil {
+		verifier = sqlPresenceVerifier{}
+	}
+
+	return verifier.Count(table, column, value, excludeID, idColumn)
+}
@@ -0,0 +1,104 @@
+package govel
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakePresenceVerifier struct {
+	counts map[string]int
+	err    error
+	calls  int
+}
+
+func (v *fakePresenceVerifier) Count(table string, column string, value string, excludeID string, idColumn string) (int, error) {
+	v.calls++
+
+	return v.counts[table+"."+column+"="+value], v.err
+}
+
+// setTestPresenceVerifier sets the verifier for the duration of the test.
+func setTestPresenceVerifier(t *testing.T, verifier PresenceVerifier) {
+	previous := presenceVerifier
+
+	SetPresenceVerifier(verifier)
+
+	t.Cleanup(func() {
+		presenceVerifier = previous
+	})
+}
+
+func TestPresenceRules(t *testing.T) {
+	verifier := &fakePresenceVerifier{counts: map[string]int{"users.email=taken@x.com": 1, "countries.code=DE": 1}}
+	setTestPresenceVerifier(t, verifier)
+
+	runValidatorCases(t, []validatorCase{
+		{
+			name:  "valid",
+			form:  formOf("email=new@x.com&country=DE"),
+			rules: Map{"email": "unique:users", "country": "exists:countries,code"},
+		},
+		{
+			name:  "invalid",
+			form:  formOf("email=taken@x.com&country=FR"),
+			rules: Map{"email": "unique:users", "country": "exists:countries,code"},
+			errors: map[string][]string{
+				"country": {"country does not exist"},
+				"email":   {"email has already been taken"},
+			},
+		},
+		{
+			name:   "column from a key of the client",
+			form:   jsonFormOf(`{"codes":{"de; DROP TABLE users":"DE"}}`),
+			rules:  Map{"codes.*": "exists:countries"},
+			errors: map[string][]string{"codes.de; DROP TABLE users": {"codes.de; DROP TABLE users does not exist"}},
+		},
+	})
+
+	if verifier.calls != 4 {
+		t.Errorf("the verifier was called %d times, want 4", verifier.calls)
+	}
+}
+
+func TestPresenceRulesStoreError(t *testing.T) {
+	storeErr := errors.New("connection refused")
+	setTestPresenceVerifier(t, &fakePresenceVerifier{err: storeErr})
+
+	_, errs := newTestForm(t, "email=a@x.com").ValidateAll(Map{"email": "required|unique:users"}, nil)
+
+	if !errors.Is(errs.Err(), storeErr) {
+		t.Errorf("Err() = %v, want the error of the store", errs.Err())
+	}
+
+	if errs.First("email") != "email could not be checked, try again later" {
+		t.Errorf("message = %q", errs.First("email"))
+	}
+}
+
+func TestPresenceRulesInvalidIdentifier(t *testing.T) {
+	setTestPresenceVerifier(t, &fakePresenceVerifier{})
+
+	_, errs := newTestForm(t, "email=a@x.com").ValidateAll(Map{"email": "unique:users;x"}, nil)
+
+	if errs.Err() == nil {
+		t.Error("an invalid table of the rules is not an error")
+	}
+
+	for _, rules := range []Map{
+		{"email": "unique:users;x"},
+		{"email": []string{"exists:users,e mail"}},
+		{"email": "unique:users,email,1,id;"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("checkPresenceRules(%v) did not panic", rules)
+				}
+			}()
+
+			checkPresenceRules(rules)
+		}()
+	}
+
+	checkPresenceRules(Map{"email": "required|unique:public.users,email,1,user_id", "code": "exists:countries"})
+}
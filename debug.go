@@ -0,0 +1,298 @@
+/*
+ * This file contains the developer error page shown when "debug: true" is set in the .yaml file.
+ */
+package govel
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// number of lines shown before and after the line that caused the panic.
+const sourceSnippetContext = 5
+
+type stackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	App      bool   `json:"app"`
+}
+
+type sourceLine struct {
+	Number  int    `json:"number"`
+	Code    string `json:"code"`
+	Current bool   `json:"current"`
+}
+
+type panicReport struct {
+	Message string
+	Origin  stackFrame
+	Stack   []stackFrame
+}
+
+type debugRequest struct {
+	Method     string              `json:"method"`
+	URL        string              `json:"url"`
+	Proto      string              `json:"proto"`
+	RemoteAddr string              `json:"remote_addr"`
+	Headers    map[string][]string `json:"headers"`
+	Query      map[string][]string `json:"query"`
+}
+
+type debugRoute struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Method      string `json:"method"`
+	Action      string `json:"action"`
+	Middlewares int    `json:"middlewares"`
+}
+
+type debugPage struct {
+	Message string       `json:"message"`
+	Origin  stackFrame   `json:"origin"`
+	Source  []sourceLine `json:"source"`
+	Stack   []stackFrame `json:"stack"`
+	Request debugRequest `json:"request"`
+	Route   *debugRoute  `json:"route"`
+}
+
+// newPanicReport captures the stack of the goroutine that is panicking.
+//
+// It must be called from the deferred function that recovered the panic.
+func newPanicReport(r interface{}) panicReport {
+	p := panicReport{Message: fmt.Sprint(r)}
+
+	pcs := make([]uintptr, 1024)
+	length := runtime.Callers(1, pcs)
+
+	frames := runtime.CallersFrames(pcs[:length])
+
+	// everything before runtime.gopanic belongs to the recovery itself
+	panicking := false
+
+	for {
+		frame, more := frames.Next()
+
+		if !panicking {
+			panicking = frame.Function == "runtime.gopanic"
+		} else {
+			p.Stack = append(p.Stack, stackFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+				App:      !strings.HasPrefix(frame.File, runtime.GOROOT()),
+			})
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	// the origin is the first frame that is not part of the runtime
+	for _, frame := range p.Stack {
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			p.Origin = frame
+			break
+		}
+	}
+
+	return p
+}
+
+// consoleMessage formats the panic for the terminal.
+func (p panicReport) consoleMessage() string {
+	return fmt.Sprintf(`%sgovel: %sPanic message recovered: %s%s
+	%sOrigin function: %s%s
+	%sOrigin file: %s%s
+	%sLine: %s%d
+
+`, colorBlue, colorRed, colorReset, p.Message, colorBlue, colorReset, p.Origin.Function, colorBlue, colorReset, p.Origin.File, colorBlue, colorReset, p.Origin.Line)
+}
+
+// readSourceSnippet returns the lines around the given line of a file.
+func readSourceSnippet(file string, line int) []sourceLine {
+	f, err := os.Open(file)
+
+	if err != nil {
+		return nil
+	}
+
+	defer f.Close()
+
+	var snippet []sourceLine
+
+	scanner := bufio.NewScanner(f)
+
+	for number := 1; scanner.Scan(); number++ {
+		if number < line-sourceSnippetContext {
+			continue
+		}
+
+		if number > line+sourceSnippetContext {
+			break
+		}
+
+		snippet = append(snippet, sourceLine{Number: number, Code: scanner.Text(), Current: number == line})
+	}
+
+	return snippet
+}
+
+// newDebugPage collects everything shown in the developer error page.
+func newDebugPage(c *Context, p panicReport) debugPage {
+	page := debugPage{
+		Message: p.Message,
+		Origin:  p.Origin,
+		Source:  readSourceSnippet(p.Origin.File, p.Origin.Line),
+		Stack:   p.Stack,
+		Request: debugRequest{
+			Method:     c.Request.Method,
+			URL:        c.Request.URL.String(),
+			Proto:      c.Request.Proto,
+			RemoteAddr: c.Request.RemoteAddr,
+			Headers:    c.Request.Header,
+			Query:      c.Request.URL.Query(),
+		},
+	}
+
+	if c.route != nil {
+		page.Route = &debugRoute{
+			Name:        c.route.name,
+			Path:        c.route.path,
+			Method:      c.route.method,
+			Action:      c.route.actionName,
+			Middlewares: len(globalMiddlewares) + len(c.route.middlewares),
+		}
+	}
+
+	return page
+}
+
+// renderDebugPage writes the developer error page as JSON or HTML depending on the "Accept" header.
+func renderDebugPage(c *Context, p panicReport) {
+	page := newDebugPage(c, p)
+
+	if wantsJSON(c.Request) {
+		c.Json(http.StatusInternalServerError, page)
+
+		return
+	}
+
+	c.ContentType("text/html; charset=utf-8")
+	c.Status(http.StatusInternalServerError)
+
+	err := debugPageTemplate.Execute(c.Buf, page)
+
+	if err != nil {
+		c.Buf.Reset()
+		c.Text(http.StatusInternalServerError, page.Message)
+	}
+}
+
+// sortedKeys returns the keys of a header-like map in alphabetical order.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+var debugPageTemplate = template.Must(template.New("debug").Funcs(template.FuncMap{
+	"sortedKeys": sortedKeys,
+	"join":       strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>{{.Message}}</title>
+	<style>
+		body { font-family: -apple-system, Helvetica, Arial, sans-serif; margin: 0; background: #f7f7f9; color: #222; }
+		header { background: #c0392b; color: #fff; padding: 24px 32px; }
+		header h1 { margin: 0 0 8px; font-size: 22px; }
+		section { background: #fff; margin: 16px 32px; padding: 16px 24px; border-radius: 4px; box-shadow: 0 1px 2px rgba(0,0,0,.1); }
+		h2 { font-size: 16px; margin-top: 0; }
+		pre { margin: 0; overflow-x: auto; }
+		.source .current { background: #fde2e1; }
+		.source .number { color: #999; display: inline-block; width: 48px; }
+		.stack li { margin-bottom: 6px; font-family: monospace; }
+		.stack .vendor { color: #999; }
+		table { border-collapse: collapse; width: 100%; }
+		td { border-top: 1px solid #eee; padding: 4px 8px; font-family: monospace; vertical-align: top; }
+		td:first-child { width: 220px; color: #555; }
+	</style>
+</head>
+<body>
+	<header>
+		<h1>{{.Message}}</h1>
+		{{if .Origin.File}}<div>{{.Origin.File}}:{{.Origin.Line}} in {{.Origin.Function}}</div>{{end}}
+	</header>
+
+	{{if .Source}}
+	<section class="source">
+		<h2>Source</h2>
+		<pre>{{range .Source}}<div{{if .Current}} class="current"{{end}}><span class="number">{{.Number}}</span>{{.Code}}</div>{{end}}</pre>
+	</section>
+	{{end}}
+
+	{{if .Stack}}
+	<section class="stack">
+		<h2>Stack trace</h2>
+		<ol>
+			{{range .Stack}}<li{{if not .App}} class="vendor"{{end}}>{{.Function}}<br>{{.File}}:{{.Line}}</li>{{end}}
+		</ol>
+	</section>
+	{{end}}
+
+	<section>
+		<h2>Request</h2>
+		<table>
+			<tr><td>Method</td><td>{{.Request.Method}}</td></tr>
+			<tr><td>URL</td><td>{{.Request.URL}}</td></tr>
+			<tr><td>Protocol</td><td>{{.Request.Proto}}</td></tr>
+			<tr><td>Remote address</td><td>{{.Request.RemoteAddr}}</td></tr>
+		</table>
+	</section>
+
+	{{if .Request.Query}}
+	<section>
+		<h2>Query</h2>
+		<table>
+			{{$query := .Request.Query}}{{range sortedKeys $query}}<tr><td>{{.}}</td><td>{{join (index $query .) ", "}}</td></tr>{{end}}
+		</table>
+	</section>
+	{{end}}
+
+	<section>
+		<h2>Headers</h2>
+		<table>
+			{{$headers := .Request.Headers}}{{range sortedKeys $headers}}<tr><td>{{.}}</td><td>{{join (index $headers .) ", "}}</td></tr>{{end}}
+		</table>
+	</section>
+
+	{{if .Route}}
+	<section>
+		<h2>Route</h2>
+		<table>
+			<tr><td>Name</td><td>{{.Route.Name}}</td></tr>
+			<tr><td>Path</td><td>{{.Route.Path}}</td></tr>
+			<tr><td>Method</td><td>{{.Route.Method}}</td></tr>
+			<tr><td>Action</td><td>{{.Route.Action}}</td></tr>
+			<tr><td>Middlewares</td><td>{{.Route.Middlewares}}</td></tr>
+		</table>
+	</section>
+	{{end}}
+</body>
+</html>
+`))
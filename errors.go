@@ -0,0 +1,165 @@
+/*
+ * This file contains the typed HTTP errors that actions can return.
+ */
+package govel
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// HTTPError is an error with an HTTP status code.
+//
+// Actions can return it to send an error response, for example:
+//
+//	return govel.NotFound()
+type HTTPError struct {
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Message is a human-readable message, it defaults to the status text.
+	Message string
+
+	// Details is optional data sent along with the error, like validation errors.
+	Details interface{}
+}
+
+func (e HTTPError) Error() string {
+	return fmt.Sprintf("%d %s", e.Status, e.Message)
+}
+
+// NewHTTPError creates an HTTPError.
+//
+// If no message is given the status text is used.
+func NewHTTPError(status int, message ...string) *HTTPError {
+	e := &HTTPError{Status: status, Message: http.StatusText(status)}
+
+	if len(message) > 0 {
+		e.Message = message[0]
+	}
+
+	return e
+}
+
+// BadRequest creates a 400 Bad Request error.
+func BadRequest(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusBadRequest, message...)
+}
+
+// Unauthorized creates a 401 Unauthorized error.
+func Unauthorized(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusUnauthorized, message...)
+}
+
+// Forbidden creates a 403 Forbidden error.
+func Forbidden(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusForbidden, message...)
+}
+
+// NotFound creates a 404 Not Found error.
+func NotFound(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusNotFound, message...)
+}
+
+// MethodNotAllowed creates a 405 Method Not Allowed error.
+func MethodNotAllowed(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusMethodNotAllowed, message...)
+}
+
+// Conflict creates a 409 Conflict error.
+func Conflict(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusConflict, message...)
+}
+
+// UnprocessableEntity creates a 422 Unprocessable Entity error.
+func UnprocessableEntity(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusUnprocessableEntity, message...)
+}
+
+// ValidationFailed creates a 422 Unprocessable Entity error with the validation errors as details.
+//
+// On API routes it is rendered as "application/problem+json" with an "errors" member keyed by field.
+func ValidationFailed(errors map[string]string) *HTTPError {
+	return &HTTPError{Status: http.StatusUnprocessableEntity, Message: validationFailedMessage, Details: errors}
+}
+
+// TooManyRequests creates a 429 Too Many Requests error.
+func TooManyRequests(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusTooManyRequests, message...)
+}
+
+// InternalServerError creates a 500 Internal Server Error error.
+func InternalServerError(message ...string) *HTTPError {
+	return NewHTTPError(http.StatusInternalServerError, message...)
+}
+
+// asHTTPError returns the HTTPError inside err, if any.
+func asHTTPError(err error) (*HTTPError, bool) {
+	var pointer *HTTPError
+
+	if errors.As(err, &pointer) && pointer != nil {
+		return pointer, true
+	}
+
+	var value HTTPError
+
+	if errors.As(err, &value) {
+		return &value, true
+	}
+
+	var validation *ValidationErrors
+
+	if errors.As(err, &validation) && validation != nil {
+		return validation.HTTPError(), true
+	}
+
+	return nil, false
+}
+
+// defaultErrorRenderer sends the error as JSON or HTML depending on the "Accept" header.
+func defaultErrorRenderer(c *Context, err *HTTPError) {
+	message := err.Message
+
+	if message == "" {
+		message = http.StatusText(err.Status)
+	}
+
+	if (c.route != nil && c.route.api) || strings.Contains(c.GetHeader("Accept"), problemContentType) {
+		c.Problem(err.Problem())
+
+		return
+	}
+
+	if wantsJSON(c.Request) {
+		body := Map{"status": err.Status, "message": message}
+
+		if err.Details != nil {
+			body["details"] = err.Details
+		}
+
+		c.Json(err.Status, body)
+
+		return
+	}
+
+	c.ContentType("text/html; charset=utf-8")
+	c.Status(err.Status)
+
+	errorPageTemplate.Execute(c.Buf, Map{"Status": err.Status, "Message": message})
+}
+
+var errorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>{{.Status}} {{.Message}}</title>
+</head>
+<body>
+	<h1>{{.Status}}</h1>
+	<p>{{.Message}}</p>
+</body>
+</html>
+`))
@@ -0,0 +1,210 @@
+/*
+ * This file contains the responses that send files.
+ */
+package govel
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File sends a file to be displayed inline by the browser.
+//
+// The Content-Type is detected from the extension or the content, and Range, If-Range
+// and If-Modified-Since requests are supported. A missing file returns a NotFound error.
+func (c *Context) File(filePath string) error {
+	return c.sendFile(filePath, "inline", filepath.Base(filePath))
+}
+
+// Download sends a file as an attachment, so the browser saves it as filename.
+func (c *Context) Download(filePath string, filename string) error {
+	return c.sendFile(filePath, "attachment", filename)
+}
+
+// Attachment sends the content of reader as an attachment named name.
+//
+// If reader is an io.ReadSeeker or an io.ReaderAt Range requests are supported,
+// otherwise it is streamed. size is sent as Content-Length if it is not negative.
+func (c *Context) Attachment(reader io.Reader, name string, size int64, modtime time.Time) error {
+	c.Headers["Content-Disposition"] = contentDisposition("attachment", name)
+
+	switch content := reader.(type) {
+	case io.ReadSeeker:
+		c.serveContent(name, modtime, content)
+
+		return nil
+
+	case io.ReaderAt:
+		if size >= 0 {
+			c.serveContent(name, modtime, io.NewSectionReader(content, 0, size))
+
+			return nil
+		}
+	}
+
+	return c.streamContent(reader, name, size, modtime)
+}
+
+// FileFromFS sends a file from a fs.FS, like an embed.FS.
+func (c *Context) FileFromFS(fsys fs.FS, name string) error {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	file, err := fsys.Open(name)
+
+	if err != nil {
+		return fileError(err)
+	}
+
+	defer file.Close()
+
+	stat, err := file.Stat()
+
+	if err != nil {
+		return fileError(err)
+	}
+
+	if stat.IsDir() {
+		return NotFound()
+	}
+
+	if content, ok := file.(io.ReadSeeker); ok {
+		c.serveContent(stat.Name(), stat.ModTime(), content)
+
+		return nil
+	}
+
+	return c.streamContent(file, stat.Name(), stat.Size(), stat.ModTime())
+}
+
+// sendFile sends a file from the disk with the given Content-Disposition.
+func (c *Context) sendFile(filePath string, disposition string, filename string) error {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		return fileError(err)
+	}
+
+	defer file.Close()
+
+	stat, err := file.Stat()
+
+	if err != nil {
+		return fileError(err)
+	}
+
+	if stat.IsDir() {
+		return NotFound()
+	}
+
+	c.Headers["Content-Disposition"] = contentDisposition(disposition, filename)
+
+	c.serveContent(filename, stat.ModTime(), file)
+
+	return nil
+}
+
+// serveContent writes the content with http.ServeContent, which handles Range, If-Range and Last-Modified.
+func (c *Context) serveContent(name string, modtime time.Time, content io.ReadSeeker) {
+	c.prepareHeaders()
+
+	http.ServeContent(c.ResponseWriter, c.Request, name, modtime, content)
+
+	c.committed = true
+}
+
+// streamContent writes a content that cannot seek, so Range requests are ignored.
+func (c *Context) streamContent(reader io.Reader, name string, size int64, modtime time.Time) error {
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if !modtime.IsZero() {
+		c.LastModified(modtime)
+
+		if c.NotModifiedSince() {
+			return nil
+		}
+	}
+
+	if size >= 0 {
+		c.Headers["Content-Length"] = strconv.FormatInt(size, 10)
+	}
+
+	return c.Stream(http.StatusOK, contentType, func(w io.Writer) error {
+		_, err := io.Copy(w, reader)
+
+		return err
+	})
+}
+
+// fileError converts the errors of opening a file to HTTPErrors.
+func fileError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return NotFound()
+
+	case errors.Is(err, fs.ErrPermission):
+		return Forbidden()
+	}
+
+	return err
+}
+
+// contentDisposition formats a Content-Disposition header with an ASCII filename
+// and, if needed, an RFC 5987 encoded UTF-8 filename.
+func contentDisposition(disposition string, filename string) string {
+	if filename == "" {
+		return disposition
+	}
+
+	var fallback strings.Builder
+	needsEncoding := false
+
+	for _, r := range filename {
+		switch {
+		case r > 126 || r < 32 || r == '"' || r == '\\':
+			fallback.WriteByte('_')
+			needsEncoding = true
+
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+
+	header := fmt.Sprintf(`%s; filename="%s"`, disposition, fallback.String())
+
+	if needsEncoding {
+		header += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+
+	return header
+}
+
+// encodeRFC5987 percent-encodes every byte that is not an attr-char.
+func encodeRFC5987(value string) string {
+	var encoded strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		b := value[i]
+
+		if (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || strings.IndexByte("!#$&+-.^_`|~", b) >= 0 {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+
+	return encoded.String()
+}
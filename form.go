@@ -24,6 +24,24 @@ func (f *Form) getVariableForValidation() interface{} {
 	return f.validationVar
 }
 
+func (f *Form) hasField(key string) bool {
+	_, exists := f.request.Form[key]
+
+	return exists
+}
+
+func (f *Form) locale() string {
+	return requestLocale(f.request)
+}
+
+func (f *Form) fields() map[string]interface{} {
+	if f.tree == nil {
+		f.tree = valuesTree(f.request.Form)
+	}
+
+	return f.tree
+}
+
 /*
  * Public methods
  */
@@ -40,30 +58,29 @@ func (f *Form) Get(value string) string {
 
 // Validate validates a form with the provided rules.
 func (f *Form) Validate(rules Map, onError OnError) (data Map, errors map[string]string) {
-	// All the valid validation rules
-	formRules := map[string]validateFunc{
-		"required":  validatorRequiredField,
-		"int":       validatorInt,
-		"string":    validatorString,
-		"min":       validatorMin,
-		"max":       validatorMax,
-		"email":     validatorEmail,
-		"optional":  validatorOptionalField,
-		"url":       validatorUrl,
-		"date":      validatorDate,
-		"true":      validatorTrue,
-		"boolean":   validatorBoolean,
-		"confirm":   validatorCofirm,
-		"alpha_num": validatorAlphaNum,
+	// start validation
+	data = make(map[string]interface{})
+	validationErrors := &ValidationErrors{}
+
+	formValidator(data, validationErrors, rules, onError, f, true)
+
+	if validationErrors.Len() > 0 {
+		return nil, validationErrors.Map()
 	}
 
-	// start validation
+	return data, nil
+}
+
+// ValidateAll validates a form like Validate, but returns every error of every field in order.
+//
+// Add the "bail" rule to a field to stop at its first error.
+func (f *Form) ValidateAll(rules Map, onError OnError) (data Map, errors *ValidationErrors) {
 	data = make(map[string]interface{})
-	errors = make(map[string]string)
+	errors = &ValidationErrors{}
 
-	formValidator(formRules, data, errors, rules, onError, f)
+	formValidator(data, errors, rules, onError, f, false)
 
-	if len(errors) > 0 {
+	if errors.Len() > 0 {
 		return nil, errors
 	}
 
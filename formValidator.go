@@ -2,125 +2,370 @@ package govel
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
+var (
+	// matches the rules of type key:value
+	ruleWithParamRegex = regexp.MustCompile(`^[a-zA-Z_]+:`)
+
+	ruleNameRegex = regexp.MustCompile(`^[a-zA-Z_]+$`)
+)
+
 // Main function for validations.
-func formValidator(formRules map[string]validateFunc, data Map, errors SMap, rules Map, onError map[string]SMap, f formInterface) {
-	for key, value := range rules {
-		var rules []string
+//
+// A key can be the path of a nested field, e.g. "address.city", and "*" matches every element
+// of a list or an object, e.g. "items.*.name". The errors are keyed by the concrete path, e.g. "items.2.name".
+//
+// The keys are validated in alphabetical order. If stopOnFirstError is false every rule of a field
+// is checked, unless the field has the "bail" rule or is empty.
+func formValidator(data Map, errors *ValidationErrors, rules Map, onError map[string]SMap, f formInterface, stopOnFirstError bool) {
+	// the top level keys of data that contain nested values
+	nested := make(map[string]bool)
+
+	keys := make([]string, 0, len(rules))
+
+	for key := range rules {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var fieldRules []string
+
+		value := rules[key]
 
 		// split the rules or get the rules
 		rulesString, isString := value.(string)
 
 		if isString {
-			rules = strings.Split(rulesString, "|")
+			fieldRules = strings.Split(rulesString, "|")
 		}
 
 		rulesSlice, isSlice := value.([]string)
 
 		if isSlice {
-			rules = rulesSlice
+			fieldRules = rulesSlice
 		}
 
 		if !isSlice && !isString /* Is not a valid type */ {
 			panic(fmt.Sprintf("The data type of the rules is not valid, only []string and string are allowed, but the type is: %T", value))
 		}
 
-		// now we iterate over the rules
-		for _, rule := range rules {
-			if f.skipValidation() {
-				f.setSkipValidation(false)
-				break
+		bail := stopOnFirstError
+
+		for _, rule := range fieldRules {
+			if rule == "bail" {
+				bail = true
 			}
+		}
 
-			// here we check if the rule is of type key:value
-			regex := regexp.MustCompile(`^[a-zA-Z]+:[^:\n]*$`)
+		for _, path := range expandRuleKey(f, key) {
+			if validateField(path, key, fieldRules, errors, onError, f, bail) {
+				if parts := strings.Split(path, "."); len(parts) > 1 && !f.hasField(path) {
+					insertNested(data, parts, f.getVariableForValidation(), false)
+					nested[parts[0]] = true
+				} else {
+					data[path] = f.getVariableForValidation()
+				}
+			}
+		}
 
-			if regex.Match([]byte(rule)) {
-				new_rule := strings.Split(rule, ":")
+		f.setSkipValidation(false)
+	}
 
-				if len(new_rule) != 2 {
-					panic(fmt.Sprintf("Rule %s not valid", rule))
-				}
+	// the indexes of the lists were inserted as keys of maps
+	for key := range nested {
+		data[key] = indexesToSlices(data[key])
+	}
+}
 
-				rule = new_rule[0]
+// validateField validates the field at path with the rules of key, it reports whether the value must be added to data.
+func validateField(path string, key string, rules []string, errors *ValidationErrors, onError map[string]SMap, f formInterface, bail bool) bool {
+	// every field starts with its own value
+	formValue, current := fieldValue(f, path)
 
-				validationCallable, exists := formRules[rule]
+	f.setSkipValidation(false)
+	f.setVariableForValidation(current)
 
-				if !exists {
-					panic(fmt.Sprintf("Rule %s not found.", rule))
-				}
+	valid := true
 
-				err := validationCallable(key, new_rule[1], f)
+	// now we iterate over the rules
+	for _, rule := range rules {
+		ctx := &ruleContext{key: path, value: formValue, form: f}
 
-				if err != nil {
-					// check if there is an error message for this case
-					error_msg, exists := onError[key][rule]
+		// here we check if the rule is of type key:value
+		if ruleWithParamRegex.MatchString(rule) {
+			newRule := strings.SplitN(rule, ":", 2)
 
-					if !exists {
-						general_error, exists := onError[key]["*"]
+			rule = newRule[0]
+			ctx.param = newRule[1]
+			ctx.hasParam = true
+		}
 
-						if !exists {
-							errors[key] = err.Error()
-						} else {
-							errors[key] = general_error
-						}
+		validationCallable, exists := findRule(rule, f)
 
-					} else {
-						errors[key] = error_msg
-					}
+		if !exists {
+			panic(fmt.Sprintf("Rule %s not found.", rule))
+		}
 
-					break
-				}
+		// do the validation
+		err := validationCallable(ctx)
 
-				if f.skipValidation() == false {
-					data[key] = f.getVariableForValidation()
-				}
+		if err != nil {
+			errors.Add(path, errorMessage(f, onError, rule, err, path, key))
+
+			if validationErr, ok := err.(*validationError); ok && validationErr.Err != nil && errors.err == nil {
+				errors.err = validationErr.Err
+			}
+
+			valid = false
+
+			// the other rules have nothing to check in an empty field
+			if bail || !isFilled(formValue, f.getVariableForValidation()) {
+				return false
+			}
+
+			continue
+		}
 
+		if f.skipValidation() {
+			return false
+		}
+	}
+
+	return valid && len(rules) > 0
+}
+
+// errorMessage returns the message of onError for the rule or "*", for the concrete path or the key, or the message of the error.
+//
+// The message is in the locale of the request and its placeholders are replaced, see ValidationMessages.
+func errorMessage(f formInterface, onError map[string]SMap, rule string, err error, keys ...string) string {
+	message := err.Error()
+
+	var params SMap
+
+	locale := f.locale()
+
+	if validationErr, ok := err.(*validationError); ok {
+		message = validationMessage(locale, validationErr.Rule)
+		params = validationErr.Params
+	}
+
+	for _, key := range keys {
+		// check if there is an error message for this case
+		if error_msg, exists := onError[key][rule]; exists {
+			message = error_msg
+			break
+		}
+
+		if general_error, exists := onError[key]["*"]; exists {
+			message = general_error
+			break
+		}
+	}
+
+	return formatMessage(locale, message, attributeName(locale, keys...), params)
+}
+
+// expandRuleKey returns the paths of the fields that match key, replacing each "*" by the existing indexes or keys.
+func expandRuleKey(f formInterface, key string) []string {
+	if !strings.Contains(key, "*") {
+		return []string{key}
+	}
+
+	paths := [][]string{nil}
+
+	for _, part := range strings.Split(key, ".") {
+		var next [][]string
+
+		for _, path := range paths {
+			if part != "*" {
+				next = append(next, appendPath(path, part))
 				continue
 			}
 
-			validationCallable, exists := formRules[rule]
+			node, _ := lookupField(f.fields(), path)
 
-			if !exists {
-				panic(fmt.Sprintf("Rule %s not found.", rule))
+			for _, child := range childKeys(node) {
+				next = append(next, appendPath(path, child))
 			}
+		}
 
-			// do the validation
-			formValue := strings.TrimSpace(f.Get(key))
+		paths = next
+	}
 
-			err := validationCallable(key, formValue, f)
+	keys := make([]string, len(paths))
 
-			if err != nil {
-				// check if there is an error message for this case
-				error_msg, exists := onError[key][rule]
+	for i, path := range paths {
+		keys[i] = strings.Join(path, ".")
+	}
 
-				if !exists {
-					general_error, exists := onError[key]["*"]
+	return keys
+}
 
-					if !exists {
-						errors[key] = err.Error()
-					} else {
-						errors[key] = general_error
-					}
+func appendPath(path []string, part string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), part)
+}
 
-				} else {
-					errors[key] = error_msg
-				}
+// fieldValue returns the trimmed value of the field at path, and the value the validation starts with.
+//
+// Lists and objects start with their value and an empty string, so rules like "array" can use them.
+func fieldValue(f formInterface, path string) (string, interface{}) {
+	if f.hasField(path) {
+		if formValue := strings.TrimSpace(f.Get(path)); formValue != "" {
+			return formValue, formValue
+		}
+	}
+
+	value, _ := lookupField(f.fields(), strings.Split(path, "."))
+
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		return "", value
+	}
+
+	formValue, _ := scalarString(value)
+
+	return formValue, formValue
+}
+
+// fieldExists reports whether the field at path was sent, even if it is empty.
+func fieldExists(f formInterface, path string) bool {
+	if f.hasField(path) {
+		return true
+	}
+
+	_, exists := lookupField(f.fields(), strings.Split(path, "."))
+
+	return exists
+}
+
+// lookupField returns the value at path in a tree of maps and slices.
+func lookupField(tree map[string]interface{}, path []string) (interface{}, bool) {
+	var node interface{} = tree
 
-				break
+	for _, part := range path {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			child, exists := value[part]
+
+			if !exists {
+				return nil, false
 			}
 
-			if f.getVariableForValidation() == nil {
-				f.setVariableForValidation(formValue)
+			node = child
+
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
 			}
 
-			if f.skipValidation() == false {
-				data[key] = f.getVariableForValidation()
+			node = value[index]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return node, true
+}
+
+// childKeys returns the indexes of a slice or the keys of a map, numeric keys sorted as numbers.
+func childKeys(node interface{}) []string {
+	var keys []string
+
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		sort.Slice(keys, func(i, j int) bool {
+			a, errA := strconv.Atoi(keys[i])
+			b, errB := strconv.Atoi(keys[j])
+
+			if errA == nil && errB == nil {
+				return a < b
 			}
+
+			return keys[i] < keys[j]
+		})
+
+	case []interface{}:
+		for i := range value {
+			keys = append(keys, strconv.Itoa(i))
+		}
+	}
+
+	return keys
+}
+
+// indexesToSlices converts the maps of data whose keys are all indexes to slices.
+//
+// Unlike normalizeNested the indexes are kept, the elements left out of data are nil.
+// Lists too sparse to keep their indexes, e.g. items[1000000], are compacted like normalizeNested does.
+func indexesToSlices(value interface{}) interface{} {
+	tree, isMap := value.(map[string]interface{})
+
+	if !isMap {
+		return value
+	}
+
+	length := 0
+
+	for key, item := range tree {
+		tree[key] = indexesToSlices(item)
+
+		index, err := strconv.Atoi(key)
+
+		if err != nil || index < 0 {
+			length = -1
+		} else if length >= 0 && index >= length {
+			length = index + 1
 		}
+	}
 
+	if length <= 0 {
+		return tree
 	}
+
+	if length > 2*len(tree)+16 {
+		return normalizeNested(tree)
+	}
+
+	list := make([]interface{}, length)
+
+	for key, item := range tree {
+		index, _ := strconv.Atoi(key)
+		list[index] = item
+	}
+
+	return list
+}
+
+// isFilled reports whether a field has something left to validate: a value, items or a file.
+func isFilled(formValue string, current interface{}) bool {
+	if formFile, isFile := current.(FormFile); isFile {
+		return formFile.File != nil || formFile.FileHeader != nil
+	}
+
+	return formValue != "" || hasItems(current)
+}
+
+// hasItems reports whether value is a list or an object with at least one element.
+func hasItems(value interface{}) bool {
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() > 0
+	}
+
+	return false
 }
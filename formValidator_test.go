@@ -0,0 +1,257 @@
+package govel
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestForm returns an urlencoded Form with the body.
+func newTestForm(t *testing.T, body string) *Form {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if err := r.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &Form{request: r}
+}
+
+// newTestJSONForm returns a JSONForm with the body.
+func newTestJSONForm(t *testing.T, body string) *JSONForm {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	values := make(map[string]interface{})
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(body)))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&values); err != nil {
+		t.Fatal(err)
+	}
+
+	return &JSONForm{request: r, values: values}
+}
+
+type validatorCase struct {
+	name   string
+	form   func(t *testing.T) formInterface
+	rules  Map
+	data   Map
+	errors map[string][]string
+}
+
+func formOf(body string) func(t *testing.T) formInterface {
+	return func(t *testing.T) formInterface {
+		return newTestForm(t, body)
+	}
+}
+
+func jsonFormOf(body string) func(t *testing.T) formInterface {
+	return func(t *testing.T) formInterface {
+		return newTestJSONForm(t, body)
+	}
+}
+
+func runValidatorCases(t *testing.T, cases []validatorCase) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := make(Map)
+			errors := &ValidationErrors{}
+
+			formValidator(data, errors, tc.rules, nil, tc.form(t), false)
+
+			got := make(map[string][]string)
+
+			for _, field := range errors.Fields() {
+				got[field] = errors.Get(field)
+			}
+
+			if tc.errors == nil {
+				tc.errors = map[string][]string{}
+			}
+
+			if !reflect.DeepEqual(got, tc.errors) {
+				t.Errorf("errors = %v, want %v", got, tc.errors)
+			}
+
+			if tc.data != nil && !reflect.DeepEqual(data, tc.data) {
+				t.Errorf("data = %#v, want %#v", data, tc.data)
+			}
+		})
+	}
+}
+
+func TestFormValidatorNested(t *testing.T) {
+	runValidatorCases(t, []validatorCase{
+		{
+			name:  "form brackets",
+			form:  formOf("address[city]=Berlin&address[zip]=10115"),
+			rules: Map{"address.city": "required", "address.zip": "required|int"},
+			data:  Map{"address": map[string]interface{}{"city": "Berlin", "zip": 10115}},
+		},
+		{
+			name:   "json object",
+			form:   jsonFormOf(`{"address":{"city":"","zip":"x"}}`),
+			rules:  Map{"address.city": "required", "address.zip": "int"},
+			errors: map[string][]string{"address.city": {"address.city is required"}, "address.zip": {"address.zip value must be an integer"}},
+		},
+		{
+			name:  "json deep path",
+			form:  jsonFormOf(`{"a":{"b":{"c":"ok"}}}`),
+			rules: Map{"a.b.c": "required|alpha"},
+			data:  Map{"a": map[string]interface{}{"b": map[string]interface{}{"c": "ok"}}},
+		},
+	})
+}
+
+func TestFormValidatorWildcard(t *testing.T) {
+	runValidatorCases(t, []validatorCase{
+		{
+			name:   "form list",
+			form:   formOf("items[0][name]=ab&items[1][name]=&items[2][name]=c"),
+			rules:  Map{"items.*.name": "required|min:2"},
+			errors: map[string][]string{"items.1.name": {"items.1.name is required"}, "items.2.name": {"items.2.name must have at least 2 characters"}},
+		},
+		{
+			name:  "json list",
+			form:  jsonFormOf(`{"items":[{"qty":"1"},{"qty":"2"}]}`),
+			rules: Map{"items": "array", "items.*.qty": "int"},
+			data:  Map{"items": []interface{}{map[string]interface{}{"qty": 1}, map[string]interface{}{"qty": 2}}},
+		},
+		{
+			name:   "json object keys",
+			form:   jsonFormOf(`{"prices":{"eur":"1","usd":"x"}}`),
+			rules:  Map{"prices.*": "numeric"},
+			errors: map[string][]string{"prices.usd": {"prices.usd must be a number"}},
+		},
+		{
+			name:   "every error of the field",
+			form:   jsonFormOf(`{"tags":["a1"]}`),
+			rules:  Map{"tags.*": "alpha|min:3"},
+			errors: map[string][]string{"tags.0": {"tags.0 may only contain letters", "tags.0 must have at least 3 characters"}},
+		},
+		{
+			name:   "bail",
+			form:   jsonFormOf(`{"tags":["a1"]}`),
+			rules:  Map{"tags.*": "bail|alpha|min:3"},
+			errors: map[string][]string{"tags.0": {"tags.0 may only contain letters"}},
+		},
+	})
+}
+
+func TestFormValidatorMissingParent(t *testing.T) {
+	runValidatorCases(t, []validatorCase{
+		{
+			name:   "required child of a missing object",
+			form:   jsonFormOf(`{}`),
+			rules:  Map{"address.city": "required"},
+			errors: map[string][]string{"address.city": {"address.city is required"}},
+		},
+		{
+			name:   "required child of a scalar",
+			form:   jsonFormOf(`{"address":"Berlin"}`),
+			rules:  Map{"address.city": "required"},
+			errors: map[string][]string{"address.city": {"address.city is required"}},
+		},
+		{
+			name:  "optional child of a missing object",
+			form:  jsonFormOf(`{}`),
+			rules: Map{"address.city": "optional|alpha"},
+			data:  Map{},
+		},
+		{
+			name:  "wildcard of a missing list",
+			form:  formOf(""),
+			rules: Map{"items.*.name": "required"},
+			data:  Map{},
+		},
+		{
+			name:   "index out of range",
+			form:   jsonFormOf(`{"items":[]}`),
+			rules:  Map{"items.0": "required"},
+			errors: map[string][]string{"items.0": {"items.0 is required"}},
+		},
+	})
+}
+
+func TestFormValidatorOrder(t *testing.T) {
+	form := newTestForm(t, "")
+	errors := &ValidationErrors{}
+
+	formValidator(make(Map), errors, Map{"c": "required", "a": "required", "b.*": "required", "b": "required"}, nil, form, false)
+
+	if got, want := errors.Fields(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+
+	_, first := newTestForm(t, "a=1").Validate(Map{"a": "alpha|min:3"}, nil)
+
+	if first["a"] != "a may only contain letters" {
+		t.Errorf("Validate stops at the first error of a field, got %v", first)
+	}
+}
+
+func TestExpandRuleKey(t *testing.T) {
+	form := newTestJSONForm(t, `{"items":[{"tags":["a","b"]},{"tags":[]},{"tags":["c"]}],"names":{"b":"x","a":"y","10":"z","2":"w"}}`)
+
+	cases := []struct {
+		key  string
+		want []string
+	}{
+		{"items", []string{"items"}},
+		{"items.*", []string{"items.0", "items.1", "items.2"}},
+		{"items.*.tags.*", []string{"items.0.tags.0", "items.0.tags.1", "items.2.tags.0"}},
+		{"names.*", []string{"names.2", "names.10", "names.a", "names.b"}},
+		{"missing.*", nil},
+		{"items.*.missing.*", nil},
+	}
+
+	for _, tc := range cases {
+		if got := expandRuleKey(form, tc.key); len(got) != len(tc.want) || (len(got) > 0 && !reflect.DeepEqual(got, tc.want)) {
+			t.Errorf("expandRuleKey(%q) = %v, want %v", tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestLookupField(t *testing.T) {
+	tree := map[string]interface{}{
+		"address": map[string]interface{}{"city": "Berlin"},
+		"items":   []interface{}{map[string]interface{}{"name": "a"}},
+		"name":    "x",
+	}
+
+	cases := []struct {
+		path   string
+		want   interface{}
+		exists bool
+	}{
+		{"address.city", "Berlin", true},
+		{"items.0.name", "a", true},
+		{"items.1.name", nil, false},
+		{"items.-1", nil, false},
+		{"items.x", nil, false},
+		{"name.first", nil, false},
+		{"missing.city", nil, false},
+	}
+
+	for _, tc := range cases {
+		got, exists := lookupField(tree, strings.Split(tc.path, "."))
+
+		if exists != tc.exists || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("lookupField(%q) = %v, %v, want %v, %v", tc.path, got, exists, tc.want, tc.exists)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * This file contains the validation of the FormRequests of the routes.
+ */
+package govel
+
+import (
+	"mime"
+	"reflect"
+	"strings"
+)
+
+// Validated returns the data validated by the FormRequest of the route, see routeModel.Validate.
+func (c *Context) Validated() Map {
+	return c.validated
+}
+
+// runFormRequest authorizes, prepares and validates the request, it reports whether the action can run.
+func (c *Context) runFormRequest(template FormRequest) (bool, error) {
+	request := newFormRequest(template)
+
+	if authorizer, ok := request.(interface{ Authorize(c *Context) bool }); ok && !authorizer.Authorize(c) {
+		return false, Forbidden()
+	}
+
+	if preparer, ok := request.(interface{ Prepare(c *Context) }); ok {
+		preparer.Prepare(c)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	isJSON := mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+
+	var data Map
+	var errors *ValidationErrors
+
+	switch {
+	case isJSON:
+		jsonForm, err := c.NewJSONForm()
+
+		if err != nil {
+			return false, err
+		}
+
+		data, errors = jsonForm.ValidateAll(request.Rules(), request.Messages())
+
+	case mediaType == "multipart/form-data":
+		multipartForm, err := c.NewMultiPartFormDataForm(DefaultMaxMemory)
+
+		if err != nil {
+			return false, BadRequest(err.Error())
+		}
+
+		data, errors = multipartForm.ValidateAll(request.Rules(), request.Messages())
+
+	default:
+		urlencodedForm, err := c.NewForm()
+
+		if err != nil {
+			return false, BadRequest(err.Error())
+		}
+
+		data, errors = urlencodedForm.ValidateAll(request.Rules(), request.Messages())
+	}
+
+	if errors == nil {
+		c.validated = data
+
+		return true, nil
+	}
+
+	// a rule could not be checked, e.g. the database is down
+	if err := errors.Err(); err != nil {
+		return false, err
+	}
+
+	// the pages get the errors in the session, so they need sessions
+	if c.route.api || wantsJSON(c.Request) || isJSON || Store == nil {
+		return false, errors
+	}
+
+	return false, c.RedirectBackWithErrors(errors.Map())
+}
+
+// newFormRequest returns a copy of a FormRequest passed as a pointer, so the requests do not share it.
+func newFormRequest(template FormRequest) FormRequest {
+	value := reflect.ValueOf(template)
+
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return template
+	}
+
+	request := reflect.New(value.Elem().Type())
+	request.Elem().Set(value.Elem())
+
+	return request.Interface().(FormRequest)
+}
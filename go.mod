@@ -5,6 +5,8 @@ go 1.19
 require (
 	github.com/gorilla/mux v1.8.0
 	github.com/gorilla/sessions v1.2.1
+	github.com/gorilla/websocket v1.5.0
+	golang.org/x/image v0.18.0
 	gopkg.in/yaml.v2 v2.4.0
 )
 
@@ -16,6 +16,7 @@ func Group(prefix string, action func()) *groupModel {
 		newGroup.prefix = newGroup.parent.prefix + newGroup.prefix
 		newGroup.middlewares = newGroup.parent.middlewares
 		newGroup.name = newGroup.parent.name
+		newGroup.api = newGroup.parent.api
 
 		currentGroupConfig.subGroups = append(newGroup.subGroups, newGroup)
 	}
@@ -82,6 +83,27 @@ func (gm *groupModel) Middlewares(middlewares ...middlewareFunction) *groupModel
 	return gm
 }
 
+// API marks the routes of a group as API routes, their errors are rendered as "application/problem+json".
+func (gm *groupModel) API() *groupModel {
+	gm.createGroup()
+
+	gm.api = true
+
+	// mark the routes
+	for _, route := range gm.routes {
+		route.API()
+	}
+
+	// mark the subgroups
+	for _, subGroup := range gm.subGroups {
+		subGroup.API()
+	}
+
+	gm.undoGroup()
+
+	return gm
+}
+
 // Internal function to "create" the group.
 func (gm *groupModel) createGroup() {
 	currentGroupConfig = gm
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"math"
 	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
 )
 
 func getErr(err error) {
@@ -47,3 +50,37 @@ func newContext(w http.ResponseWriter, r *http.Request) *Context {
 
 	return c
 }
+
+// toRouteFunction converts an action to a routeFunction.
+func toRouteFunction[A Action](action A) routeFunction {
+	value := reflect.ValueOf(action)
+
+	// the action can be of a named type like "type Handler func(c *Context) error", so it is converted
+	if value.Type().NumOut() == 1 {
+		return value.Convert(reflect.TypeOf(routeFunction(nil))).Interface().(routeFunction)
+	}
+
+	function := value.Convert(reflect.TypeOf(func(c *Context) {})).Interface().(func(c *Context))
+
+	return func(c *Context) error {
+		function(c)
+
+		return nil
+	}
+}
+
+// functionName returns the full name of a function.
+func functionName(function interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(function).Pointer()).Name()
+}
+
+// wantsJSON reports whether the client prefers a JSON response over HTML.
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+
+	if !strings.Contains(accept, "json") {
+		return false
+	}
+
+	return negotiate(accept, "text/html", "application/json", problemContentType) != "text/html"
+}
@@ -0,0 +1,32 @@
+package govel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testHandler func(c *Context) error
+
+type testVoidHandler func(c *Context)
+
+func TestToRouteFunction(t *testing.T) {
+	errTeapot := NewHTTPError(http.StatusTeapot, "teapot")
+
+	cases := map[string]routeFunction{
+		"func(c *Context)":       toRouteFunction(func(c *Context) { c.statusCode = http.StatusTeapot }),
+		"func(c *Context) error": toRouteFunction(func(c *Context) error { return errTeapot }),
+		"named error handler":    toRouteFunction(testHandler(func(c *Context) error { return errTeapot })),
+		"named handler":          toRouteFunction(testVoidHandler(func(c *Context) { c.statusCode = http.StatusTeapot })),
+	}
+
+	for name, action := range cases {
+		c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if err := action(c); err != nil && err != error(errTeapot) {
+			t.Errorf("%s: unexpected error %v", name, err)
+		} else if err == nil && c.statusCode != http.StatusTeapot {
+			t.Errorf("%s: the action was not called", name)
+		}
+	}
+}
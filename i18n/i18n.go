@@ -0,0 +1,364 @@
+// Package i18n loads translation files and translates messages, with placeholders and pluralization.
+//
+// The translations of a locale are YAML or JSON files in a directory:
+//
+//	lang/en.yaml          keys "welcome", "cart.items", ...
+//	lang/es.json
+//	lang/es/validation.yaml   keys "validation.required", ...
+//
+// Nested objects are flattened with dots, so {"cart": {"items": "..."}} is the key "cart.items".
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Bundle is a set of translations keyed by locale.
+type Bundle struct {
+	mu sync.RWMutex
+
+	fallback string
+
+	messages map[string]map[string]string
+}
+
+// New creates an empty Bundle, fallback is the locale used when a message is missing in the requested locale.
+func New(fallback string) *Bundle {
+	return &Bundle{fallback: Normalize(fallback), messages: make(map[string]map[string]string)}
+}
+
+// Fallback returns the fallback locale.
+func (b *Bundle) Fallback() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.fallback
+}
+
+// SetFallback changes the fallback locale.
+func (b *Bundle) SetFallback(locale string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.fallback = Normalize(locale)
+}
+
+// LoadDir loads the translation files of a directory, see the package documentation for the layout.
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir), ".")
+}
+
+// LoadFS loads the translation files of a directory of a fs.FS, like an embed.FS.
+//
+// The messages override the messages already loaded with the same key.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+
+		if entry.IsDir() {
+			// lang/es/validation.yaml
+			files, err := fs.ReadDir(fsys, name)
+
+			if err != nil {
+				return err
+			}
+
+			for _, file := range files {
+				group := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+
+				if file.IsDir() || !isTranslationFile(file.Name()) {
+					continue
+				}
+
+				if err := b.loadFile(fsys, path.Join(name, file.Name()), entry.Name(), group); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if !isTranslationFile(entry.Name()) {
+			continue
+		}
+
+		// lang/es.yaml
+		locale := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+
+		if err := b.loadFile(fsys, name, locale, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Bundle) loadFile(fsys fs.FS, name string, locale string, prefix string) error {
+	content, err := fs.ReadFile(fsys, name)
+
+	if err != nil {
+		return err
+	}
+
+	var messages map[string]interface{}
+
+	if path.Ext(name) == ".json" {
+		err = json.Unmarshal(content, &messages)
+	} else {
+		err = yaml.Unmarshal(content, &messages)
+	}
+
+	if err != nil {
+		return fmt.Errorf("i18n: cannot parse %s: %w", name, err)
+	}
+
+	if prefix != "" {
+		messages = map[string]interface{}{prefix: messages}
+	}
+
+	b.Add(locale, messages)
+
+	return nil
+}
+
+func isTranslationFile(name string) bool {
+	switch path.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+
+	return false
+}
+
+// Add adds messages to a locale, nested maps are flattened with dots.
+func (b *Bundle) Add(locale string, messages map[string]interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	locale = Normalize(locale)
+
+	if b.messages[locale] == nil {
+		b.messages[locale] = make(map[string]string)
+	}
+
+	flatten(b.messages[locale], "", messages)
+}
+
+func flatten(dst map[string]string, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flatten(dst, joinKey(prefix, key), child)
+		}
+
+	// maps decoded by yaml.v2
+	case map[interface{}]interface{}:
+		for key, child := range v {
+			flatten(dst, joinKey(prefix, fmt.Sprint(key)), child)
+		}
+
+	case nil:
+
+	default:
+		dst[prefix] = fmt.Sprint(v)
+	}
+}
+
+func joinKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+// Locales returns the locales that have messages, sorted.
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	locales := make([]string, 0, len(b.messages))
+
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+
+	sort.Strings(locales)
+
+	return locales
+}
+
+// Has reports whether the bundle has messages for a locale.
+func (b *Bundle) Has(locale string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	_, exists := b.messages[Normalize(locale)]
+
+	return exists
+}
+
+// Lookup returns the message of a key in a locale, then in its language, e.g. "es" for "es-MX", then in the fallback locale.
+func (b *Bundle) Lookup(locale string, key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.lookup(key, locale, Language(locale), b.fallback, Language(b.fallback))
+}
+
+// LookupLocale is like Lookup, but without the fallback locale.
+func (b *Bundle) LookupLocale(locale string, key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.lookup(key, locale, Language(locale))
+}
+
+func (b *Bundle) lookup(key string, locales ...string) (string, bool) {
+	for _, locale := range locales {
+		if message, exists := b.messages[Normalize(locale)][key]; exists {
+			return message, true
+		}
+	}
+
+	return "", false
+}
+
+// T translates a key, the key is returned if it has no message.
+//
+// The placeholders of the message, e.g. :name, are replaced by args. If args has a "count" the message
+// is pluralized, its forms are separated by "|":
+//
+//	apples: "one apple|:count apples"
+//	apples: "{0} no apples|{1} one apple|[2,*] :count apples"
+func (b *Bundle) T(locale string, key string, args map[string]interface{}) string {
+	message, exists := b.Lookup(locale, key)
+
+	if !exists {
+		return key
+	}
+
+	if count, hasCount := args["count"]; hasCount {
+		if n, ok := toFloat(count); ok {
+			message = Choose(message, n, locale)
+		}
+	}
+
+	return Replace(message, args)
+}
+
+// Replace replaces the placeholders of a message, the longest first so :values is not taken for :value.
+func Replace(message string, args map[string]interface{}) string {
+	if len(args) == 0 || !strings.Contains(message, ":") {
+		return message
+	}
+
+	names := make([]string, 0, len(args))
+
+	for name := range args {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+
+	pairs := make([]string, 0, 2*len(names))
+
+	for _, name := range names {
+		pairs = append(pairs, ":"+name, fmt.Sprint(args[name]))
+	}
+
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// Normalize formats a locale as a lowercase language and an uppercase region, e.g. "es_mx" as "es-MX".
+func Normalize(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+
+	parts[0] = strings.ToLower(parts[0])
+
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// Language returns the language of a locale, e.g. "es" for "es-MX".
+func Language(locale string) string {
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		return strings.ToLower(locale[:i])
+	}
+
+	return strings.ToLower(locale)
+}
+
+// Match returns the locale of the bundle that best matches an Accept-Language header, or an empty string.
+func (b *Bundle) Match(acceptLanguage string) string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+
+	var preferences []weighted
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		locale := strings.TrimSpace(fields[0])
+
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		quality := 1.0
+
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if quality > 0 {
+			preferences = append(preferences, weighted{locale: Normalize(locale), quality: quality})
+		}
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	for _, preference := range preferences {
+		if b.Has(preference.locale) {
+			return preference.locale
+		}
+
+		if language := Language(preference.locale); b.Has(language) {
+			return language
+		}
+	}
+
+	return ""
+}
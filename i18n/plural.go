@@ -0,0 +1,212 @@
+package i18n
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Choose returns the form of a pluralized message for n.
+//
+// Forms can start with an exact value, {0}, or a range, [2,10] or [11,*], which are checked first.
+// Otherwise the form is chosen with the plural rules of the language of locale.
+func Choose(message string, n float64, locale string) string {
+	forms := strings.Split(message, "|")
+
+	if len(forms) == 1 {
+		return message
+	}
+
+	var plain []string
+
+	for _, form := range forms {
+		form = strings.TrimSpace(form)
+
+		if matched, text, explicit := matchExplicit(form, n); explicit {
+			if matched {
+				return text
+			}
+
+			continue
+		}
+
+		plain = append(plain, form)
+	}
+
+	if len(plain) == 0 {
+		return strings.TrimSpace(forms[len(forms)-1])
+	}
+
+	index := pluralIndex(Language(locale), n)
+
+	if index >= len(plain) {
+		index = len(plain) - 1
+	}
+
+	return plain[index]
+}
+
+// matchExplicit checks the {n} and [a,b] prefixes of a form.
+func matchExplicit(form string, n float64) (matched bool, text string, explicit bool) {
+	if len(form) == 0 || (form[0] != '{' && form[0] != '[') {
+		return false, form, false
+	}
+
+	closing := "}"
+
+	if form[0] == '[' {
+		closing = "]"
+	}
+
+	end := strings.Index(form, closing)
+
+	if end < 0 {
+		return false, form, false
+	}
+
+	condition := form[1:end]
+	text = strings.TrimSpace(form[end+1:])
+
+	if form[0] == '{' {
+		value, err := strconv.ParseFloat(strings.TrimSpace(condition), 64)
+
+		return err == nil && value == n, text, err == nil
+	}
+
+	bounds := strings.SplitN(condition, ",", 2)
+
+	if len(bounds) != 2 {
+		return false, form, false
+	}
+
+	min, max := math.Inf(-1), math.Inf(1)
+
+	if bound := strings.TrimSpace(bounds[0]); bound != "*" {
+		value, err := strconv.ParseFloat(bound, 64)
+
+		if err != nil {
+			return false, form, false
+		}
+
+		min = value
+	}
+
+	if bound := strings.TrimSpace(bounds[1]); bound != "*" {
+		value, err := strconv.ParseFloat(bound, 64)
+
+		if err != nil {
+			return false, form, false
+		}
+
+		max = value
+	}
+
+	return n >= min && n <= max, text, true
+}
+
+// pluralIndex returns the index of the plural form of n in a language, a simplified version of the CLDR rules.
+func pluralIndex(language string, n float64) int {
+	i := int64(math.Abs(n))
+	integer := float64(i) == math.Abs(n)
+
+	switch language {
+	// a single form
+	case "ja", "zh", "ko", "vi", "th", "id", "ms":
+		return 0
+
+	// 0 and 1 are singular
+	case "fr", "pt":
+		if n >= 0 && n < 2 {
+			return 0
+		}
+
+		return 1
+
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		switch {
+		case !integer:
+			return 2
+
+		case i%10 == 1 && i%100 != 11:
+			return 0
+
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return 1
+		}
+
+		return 2
+
+	case "pl":
+		switch {
+		case !integer:
+			return 2
+
+		case i == 1:
+			return 0
+
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return 1
+		}
+
+		return 2
+
+	case "cs", "sk":
+		switch {
+		case !integer:
+			return 2
+
+		case i == 1:
+			return 0
+
+		case i >= 2 && i <= 4:
+			return 1
+		}
+
+		return 2
+	}
+
+	if n == 1 {
+		return 0
+	}
+
+	return 1
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+
+		return n, err == nil
+	case interface{ String() string }:
+		n, err := strconv.ParseFloat(v.String(), 64)
+
+		return n, err == nil
+	}
+
+	return 0, false
+}
@@ -0,0 +1,240 @@
+package govel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MaxJSONBodySize is the maximum size in bytes of the JSON request bodies read by BindJSON and ValidateJSON.
+var MaxJSONBodySize int64 = 1 << 20
+
+/*
+ * Private methods
+ */
+
+func (f *JSONForm) skipValidation() bool {
+	return f.skip
+}
+
+func (f *JSONForm) setSkipValidation(value bool) {
+	f.skip = value
+}
+
+func (f *JSONForm) setVariableForValidation(v interface{}) {
+	f.validationVar = v
+}
+
+func (f *JSONForm) getVariableForValidation() interface{} {
+	return f.validationVar
+}
+
+func (f *JSONForm) hasField(key string) bool {
+	_, exists := f.values[key]
+
+	return exists
+}
+
+func (f *JSONForm) locale() string {
+	return requestLocale(f.request)
+}
+
+func (f *JSONForm) fields() map[string]interface{} {
+	return f.values
+}
+
+/*
+ * Public methods
+ */
+
+// Gets all the fields of the JSON object.
+func (f *JSONForm) GetAll() map[string]interface{} {
+	return f.values
+}
+
+// Gets the value of a field as a string.
+//
+// Numbers and booleans are formatted, null, objects and arrays return an empty string.
+func (f *JSONForm) Get(key string) string {
+	switch value := f.values[key].(type) {
+	case string:
+		return value
+
+	case json.Number:
+		return value.String()
+
+	case bool:
+		if value {
+			return "true"
+		}
+
+		return "false"
+	}
+
+	return ""
+}
+
+// Validate validates the JSON object with the provided rules.
+func (f *JSONForm) Validate(rules Map, onError OnError) (data Map, errors map[string]string) {
+	// start validation
+	data = make(map[string]interface{})
+	validationErrors := &ValidationErrors{}
+
+	formValidator(data, validationErrors, rules, onError, f, true)
+
+	if validationErrors.Len() > 0 {
+		return nil, validationErrors.Map()
+	}
+
+	return data, nil
+}
+
+// ValidateAll validates the JSON object like Validate, but returns every error of every field in order.
+//
+// Add the "bail" rule to a field to stop at its first error.
+func (f *JSONForm) ValidateAll(rules Map, onError OnError) (data Map, errors *ValidationErrors) {
+	data = make(map[string]interface{})
+	errors = &ValidationErrors{}
+
+	formValidator(data, errors, rules, onError, f, false)
+
+	if errors.Len() > 0 {
+		return nil, errors
+	}
+
+	return data, nil
+}
+
+/*
+ * Context methods
+ */
+
+// NewJSONForm decodes the JSON object of the request body to validate it like a form.
+func (c *Context) NewJSONForm() (JSONForm, error) {
+	body, err := c.readJSONBody()
+
+	if err != nil {
+		return JSONForm{}, err
+	}
+
+	values := make(map[string]interface{})
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	err = decodeJSONBody(decoder, &values)
+
+	return JSONForm{request: c.Request, values: values}, err
+}
+
+// ValidateJSON validates the JSON object of the request body with the same rules as Form.Validate.
+//
+// If the body is not a valid JSON object the error is returned under the "body" key.
+func (c *Context) ValidateJSON(rules Map, onError OnError) (data Map, errors map[string]string) {
+	form, err := c.NewJSONForm()
+
+	if err != nil {
+		return nil, map[string]string{"body": jsonErrorMessage(err)}
+	}
+
+	return form.Validate(rules, onError)
+}
+
+// BindJSON decodes the JSON request body into ptr.
+//
+// Unknown fields are rejected. The body is limited to MaxJSONBodySize bytes.
+// The errors are HTTPErrors with a clear message, so they can be returned by the action.
+func (c *Context) BindJSON(ptr interface{}) error {
+	body, err := c.readJSONBody()
+
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	return decodeJSONBody(decoder, ptr)
+}
+
+// readJSONBody reads the request body once, so it can be bound and validated in the same request.
+func (c *Context) readJSONBody() ([]byte, error) {
+	if c.jsonBody != nil {
+		return c.jsonBody, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.ResponseWriter, c.Request.Body, MaxJSONBodySize))
+
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+
+		if errors.As(err, &maxBytesError) {
+			return nil, NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body must not be larger than %d bytes", MaxJSONBodySize))
+		}
+
+		return nil, err
+	}
+
+	c.jsonBody = body
+
+	return body, nil
+}
+
+// decodeJSONBody decodes a single JSON value and converts the errors to HTTPErrors.
+func decodeJSONBody(decoder *json.Decoder, ptr interface{}) error {
+	err := decoder.Decode(ptr)
+
+	if err == nil {
+		// there must be nothing after the value, not even a stray "}" or "]"
+		if err := decoder.Decode(&struct{}{}); err != io.EOF {
+			return BadRequest("Request body must only contain a single JSON value")
+		}
+
+		return nil
+	}
+
+	var invalidUnmarshalError *json.InvalidUnmarshalError
+
+	if errors.As(err, &invalidUnmarshalError) {
+		panic(err.Error())
+	}
+
+	return BadRequest(jsonErrorMessage(err))
+}
+
+// jsonErrorMessage returns a message for the errors of decoding a JSON body.
+func jsonErrorMessage(err error) string {
+	var syntaxError *json.SyntaxError
+	var unmarshalTypeError *json.UnmarshalTypeError
+	var httpError *HTTPError
+
+	switch {
+	case errors.As(err, &httpError):
+		return httpError.Message
+
+	case errors.As(err, &syntaxError):
+		return fmt.Sprintf("Request body contains badly-formed JSON (at position %d)", syntaxError.Offset)
+
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "Request body contains badly-formed JSON"
+
+	case errors.As(err, &unmarshalTypeError):
+		if unmarshalTypeError.Field == "" {
+			return "Request body must be a JSON object"
+		}
+
+		return fmt.Sprintf("Request body contains an invalid value for the %q field (at position %d)", unmarshalTypeError.Field, unmarshalTypeError.Offset)
+
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return fmt.Sprintf("Request body contains unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+
+	case errors.Is(err, io.EOF):
+		return "Request body must not be empty"
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,39 @@
+package govel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBindJSONSingleValue(t *testing.T) {
+	cases := []struct {
+		body  string
+		valid bool
+	}{
+		{`{"a":1}`, true},
+		{"{\"a\":1}\n", true},
+		{`{"a":1}}`, false},
+		{`{"a":1}]`, false},
+		{`{"a":1}{"a":2}`, false},
+		{`{"a":1} 2`, false},
+		{`{"a":1`, false},
+		{``, false},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+		r.Header.Set("Content-Type", "application/json")
+
+		c := newContext(httptest.NewRecorder(), r)
+
+		var v struct {
+			A int `json:"a"`
+		}
+
+		if err := c.BindJSON(&v); (err == nil) != tc.valid {
+			t.Errorf("BindJSON(%q) = %v, want valid %v", tc.body, err, tc.valid)
+		}
+	}
+}
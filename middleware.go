@@ -4,119 +4,202 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
-	"runtime"
 )
 
 // callFunction is the function between the request and the action.
-func callFunction(action routeFunction, middlewares middlewaresFunctions) http.HandlerFunc {
+func callFunction(route *routeModel) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		c := newContext(rw, r)
+		c.route = route
 
-		defer func() {
-			// finish the request
+		defer c.finish()
 
-			// save the sessions if any
-			if len(c.sessions) > 0 {
-				for _, session := range c.sessions {
-					// check if the sessions has new values
-					if reflect.DeepEqual(session.originalState, session.session.Values) {
-						continue
-					}
+		// Middlewares must return two values.
+		// If it returns 0, the request will continue as normal, but if it returns 1, the request will abort.
+
+		var cancel int = 0
 
-					session.session.Save(c.Request, c.ResponseWriter)
+		if globalMiddlewares != nil {
+			for _, middleware := range globalMiddlewares {
+				if middleware(c) != cancel {
+					cancel = 1
+					break
 				}
 			}
+		}
 
-			// set the headers
-			for key, value := range c.Headers {
-				c.ResponseWriter.Header().Set(key, value)
+		if route.middlewares != nil && cancel == 0 {
+			for _, middleware := range route.middlewares {
+				if middleware(c) != cancel {
+					cancel = 1
+					break
+				}
 			}
+		}
 
-			// write the rest of the response
-			c.ResponseWriter.WriteHeader(c.statusCode)
-			c.ResponseWriter.Write(c.Buf.Bytes())
+		if cancel == 0 && route.formRequest != nil {
+			continueRequest, err := c.runFormRequest(route.formRequest)
 
-			// recover from panic
-			if r := recover(); r != nil {
-				if panicHandlerFunc != nil {
-					panicHandlerFunc(c, r)
-				} else {
+			if err != nil {
+				c.handleError(err)
+			}
 
-					// get the stack trace of the panic
-					actionFunctionName := runtime.FuncForPC(reflect.ValueOf(action).Pointer()).Name()
+			if !continueRequest {
+				cancel = 1
+			}
+		}
 
-					stack := make([]uintptr, 1024)
+		if cancel == 0 {
+			if err := route.action(c); err != nil {
+				c.handleError(err)
+			}
+		}
+	}
+}
 
-					length := runtime.Callers(2, stack)
+func httpHandler(function routeFunction) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		c := newContext(rw, r)
 
-					var skip int
+		defer c.finish()
 
-					for i := 0; i < length; i++ {
-						pc := stack[i]
+		if err := function(c); err != nil {
+			c.handleError(err)
+		}
+	}
+}
 
-						funcPtr := runtime.FuncForPC(pc)
+// finish recovers from a panic (if any) and writes the response.
+//
+// It must always be deferred, otherwise recover will not stop the panic.
+func (c *Context) finish() {
+	// recover from panic before anything is written
+	if r := recover(); r != nil {
+		c.recoverFromPanic(r)
+	}
 
-						if funcPtr.Name() == actionFunctionName {
-							skip = i + 1
+	// streamed responses are already written
+	if !c.committed {
+		// the last registered function runs first, like unwinding the middlewares
+		for i := len(c.beforeWrite) - 1; i >= 0; i-- {
+			c.beforeWrite[i](c)
+		}
 
-							break
-						}
+		// write the rest of the response
+		c.writeHeader()
+		c.ResponseWriter.Write(c.Buf.Bytes())
+	}
 
-					}
+	// close the request body
+	c.Request.Body.Close()
+}
 
-					pc, file, line, _ := runtime.Caller(skip)
-					caller := runtime.FuncForPC(pc)
+// prepareHeaders saves the sessions and copies the headers to the ResponseWriter.
+func (c *Context) prepareHeaders() {
+	// save the sessions if any
+	if len(c.sessions) > 0 {
+		for _, session := range c.sessions {
+			// check if the sessions has new values
+			if reflect.DeepEqual(session.originalState, session.session.Values) {
+				continue
+			}
 
-					// format the message
-					panicMsg := fmt.Sprintf(`%sgovel: %sPanic message recovered: %s%s
-	%sOrigin function: %s%s
-	%sOrigin file: %s%s
-	%sLine: %s%d
+			session.session.Save(c.Request, c.ResponseWriter)
+		}
+	}
 
-`, colorBlue, colorRed, colorReset, r, colorBlue, colorReset, caller.Name(), colorBlue, colorReset, file, colorBlue, colorReset, line)
+	// set the headers
+	for key, value := range c.Headers {
+		c.ResponseWriter.Header().Set(key, value)
+	}
+}
 
-					fmt.Print(panicMsg)
-				}
-			}
+// writeHeader sends the headers and the status code, after that only the body can be written.
+func (c *Context) writeHeader() {
+	c.prepareHeaders()
 
-			// close the request body
-			c.Request.Body.Close()
+	c.ResponseWriter.WriteHeader(c.statusCode)
 
-		}()
+	c.committed = true
+}
 
-		// Middlewares must return two values.
-		// If it returns 0, the request will continue as normal, but if it returns 1, the request will abort.
+// the headers that describe the body or the redirect, discarded with them when an error replaces the response.
+var bodyHeaders = []string{"Content-Type", "Content-Length", "Content-Encoding", "Content-Disposition", "Content-Range", "ETag", "Last-Modified", "Location"}
 
-		var cancel int = 0
+// discardBodyHeaders deletes the headers of the discarded body, the others like CORS or Vary are kept.
+func (c *Context) discardBodyHeaders() {
+	for _, header := range bodyHeaders {
+		delete(c.Headers, header)
+	}
+}
 
-		if globalMiddlewares != nil {
-			for _, middleware := range globalMiddlewares {
-				if middleware(c) != cancel {
-					cancel = 1
-					break
-				}
-			}
-		}
+// recoverFromPanic discards the partial response and replaces it with a 500 response.
+func (c *Context) recoverFromPanic(r interface{}) {
+	p := newPanicReport(r)
 
-		if middlewares != nil && cancel == 0 {
-			for _, middleware := range middlewares {
-				if middleware(c) != cancel {
-					cancel = 1
-					break
-				}
-			}
+	// the response was already sent, it can only be reported
+	if c.committed {
+		if errorReporterFunc != nil {
+			errorReporterFunc(c, fmt.Errorf("panic: %s", p.Message))
 		}
 
-		if cancel == 0 {
-			action(c)
-		}
+		fmt.Print(p.consoleMessage())
+
+		return
+	}
+
+	// discard everything the action wrote before panicking
+	c.Buf.Reset()
+	c.discardBodyHeaders()
+	c.statusCode = http.StatusInternalServerError
+
+	if errorReporterFunc != nil {
+		errorReporterFunc(c, fmt.Errorf("panic: %s", p.Message))
+	}
+
+	if panicHandlerFunc != nil {
+		panicHandlerFunc(c, r)
+
+		return
 	}
+
+	fmt.Print(p.consoleMessage())
+
+	if debugMode {
+		renderDebugPage(c, p)
+
+		return
+	}
+
+	errorRendererFunc(c, InternalServerError())
 }
 
-func httpHandler(function routeFunction) http.HandlerFunc {
-	return func(rw http.ResponseWriter, r *http.Request) {
-		c := newContext(rw, r)
+// handleError discards the partial response and renders the error returned by an action.
+func (c *Context) handleError(err error) {
+	httpError, isHTTPError := asHTTPError(err)
+
+	if errorReporterFunc != nil && (!isHTTPError || httpError.Status >= http.StatusInternalServerError) {
+		errorReporterFunc(c, err)
+	}
+
+	// the response was already sent
+	if c.committed {
+		return
+	}
 
-		function(c)
+	c.Buf.Reset()
+	c.discardBodyHeaders()
+	c.statusCode = http.StatusInternalServerError
+
+	if !isHTTPError {
+		if debugMode {
+			renderDebugPage(c, panicReport{Message: err.Error()})
+
+			return
+		}
+
+		httpError = InternalServerError()
 	}
+
+	errorRendererFunc(c, httpError)
 }
@@ -2,9 +2,9 @@ package govel
 
 import (
 	"bytes"
-	"fmt"
 	"mime/multipart"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/sessions"
 )
@@ -30,6 +30,27 @@ type Context struct {
 	statusCode int
 
 	sessions []Session
+
+	// the route being served, nil for the 404 and 405 handlers.
+	route *routeModel
+
+	// functions called before the response is written, see BeforeWrite.
+	beforeWrite []func(c *Context)
+
+	// the time set with LastModified.
+	lastModified time.Time
+
+	// indicates that the headers were sent and Buf will not be written.
+	committed bool
+
+	// the flash values already read in this request.
+	flashes map[string]interface{}
+
+	// the JSON request body, read once by BindJSON and ValidateJSON.
+	jsonBody []byte
+
+	// the data validated by the FormRequest of the route, see Validated.
+	validated Map
 }
 
 /*
@@ -40,13 +61,28 @@ type routeModel struct {
 	unique_id   string
 	path        string
 	action      routeFunction
+	actionName  string
 	middlewares middlewaresFunctions
 	name        string
 	method      string
 	pathUpdated bool
+	api         bool
+	formRequest FormRequest
 }
 
-type routeFunction func(c *Context)
+// Action is the type of the actions of the routes, a func(c *Context) or a func(c *Context) error.
+type Action interface {
+	~func(c *Context) | ~func(c *Context) error
+}
+
+// routeFunction is the internal form of every action.
+//
+// Actions of type func(c *Context) are wrapped to always return nil.
+type routeFunction func(c *Context) error
+
+type errorRenderer func(c *Context, err *HTTPError)
+
+type errorReporter func(c *Context, err error)
 
 type middlewaresFunctions []middlewareFunction
 
@@ -64,6 +100,7 @@ type groupModel struct {
 	middlewares middlewaresFunctions
 	name        string
 	subGroups   []*groupModel
+	api         bool
 }
 
 /*
@@ -76,6 +113,7 @@ type sqlStruct struct {
 	Password string `yaml:"password"`
 	Database string `yaml:"database"`
 	Driver   string `yaml:"driver"`
+	Dsn      string `yaml:"dsn"`
 }
 
 type staticStruct struct {
@@ -83,15 +121,28 @@ type staticStruct struct {
 	Dir  string `yaml:"dir"`
 }
 
+type viewsStruct struct {
+	Dir       string `yaml:"dir"`
+	Extension string `yaml:"extension"`
+}
+
 type keysStruct struct {
 	Sessions string `yaml:"sessions"`
 }
 
+type i18nStruct struct {
+	Dir    string `yaml:"dir"`
+	Locale string `yaml:"locale"`
+}
+
 type configYamlFile struct {
 	Port   int          `yaml:"port"`
+	Debug  bool         `yaml:"debug"`
 	Sql    sqlStruct    `yaml:"sql"`
 	Static staticStruct `yaml:"static"`
 	Keys   keysStruct   `yaml:"keys"`
+	Views  viewsStruct  `yaml:"views"`
+	I18n   i18nStruct   `yaml:"i18n"`
 }
 
 /*
@@ -104,6 +155,9 @@ type Form struct {
 
 	skip          bool
 	validationVar interface{}
+
+	// the values as nested maps and slices, built the first time a nested key is validated.
+	tree map[string]interface{}
 }
 
 type MultipartFormData struct {
@@ -112,6 +166,19 @@ type MultipartFormData struct {
 
 	skip          bool
 	validationVar interface{}
+
+	// the values as nested maps and slices, built the first time a nested key is validated.
+	tree map[string]interface{}
+}
+
+// JSONForm is a decoded JSON request body, used to validate it like a form.
+type JSONForm struct {
+	request *http.Request
+
+	values map[string]interface{}
+
+	skip          bool
+	validationVar interface{}
 }
 
 type formInterface interface {
@@ -123,9 +190,19 @@ type formInterface interface {
 
 	getVariableForValidation() interface{}
 
+	hasField(string) bool
+
+	fields() map[string]interface{}
+
+	locale() string
+
 	Get(string) string
 }
 
+// FormFile is an uploaded file of a MultipartFormData.
+//
+// In the data of a validation File is only open for the "requiredFile" and "optionalFile" rules,
+// the other file rules close it after their check, so open it again with FileHeader.Open.
 type FormFile struct {
 	File       multipart.File
 	FileHeader *multipart.FileHeader
@@ -145,13 +222,81 @@ type OnError map[string]SMap
 // form is only used when a function needs direct access to the form.
 type validateFunc func(key string, value string, form formInterface) error
 
+// RuleFunc is a validation rule registered with RegisterRule.
+//
+// It returns an error if the field is not valid, the message can be overridden with OnError.
+type RuleFunc func(ctx RuleContext) error
+
+// RuleContext gives a validation rule access to the field being validated.
+type RuleContext interface {
+	// Key returns the name of the field.
+	Key() string
+
+	// Value returns the value of the field, without leading and trailing spaces.
+	Value() string
+
+	// Param returns the parameter of the rule, e.g. "a,b" for "in:a,b", or an empty string.
+	Param() string
+
+	// Params returns the parameter of the rule split by commas.
+	Params() []string
+
+	// Form returns the form being validated, a *Form, a *MultipartFormData or a *JSONForm.
+	Form() FormValues
+
+	// Current returns the value of the field converted by the previous rules, e.g. an int after the "int" rule.
+	Current() interface{}
+
+	// SetCurrent sets the converted value, it is the value returned in "data" unless another rule changes it.
+	SetCurrent(v interface{})
+
+	// Skip stops the validation of the field and leaves it out of "data", like the "optional" rule does with empty values.
+	Skip()
+}
+
+// FormValues is implemented by every type of form.
+type FormValues interface {
+	// Get returns the value of a field.
+	Get(key string) string
+}
+
+// FormRequest is a request validated before the action of a route, see routeModel.Validate.
+//
+// It can also have the methods:
+//
+//	// Authorize rejects the request with a 403 Forbidden error if it returns false.
+//	Authorize(c *govel.Context) bool
+//
+//	// Prepare is called before the validation, e.g. to normalize the input.
+//	Prepare(c *govel.Context)
+type FormRequest interface {
+	// Rules returns the rules of the fields, like in Form.Validate.
+	Rules() Map
+
+	// Messages returns the messages that override the default ones, it can be nil.
+	Messages() OnError
+}
+
+// validationError is the error of a built-in rule.
+//
+// Rule is the key of its message in ValidationMessages and Params are the placeholders of the message.
 type validationError struct {
-	Format string
+	Rule   string
 	Key    string
+	Params SMap
+
+	// Err is the error that kept the rule from being checked, e.g. of the database, see ValidationErrors.Err.
+	Err error
+}
+
+func (e *validationError) Unwrap() error {
+	return e.Err
 }
 
 func (e *validationError) Error() string {
-	return fmt.Sprintf(e.Format, e.Key)
+	locale := Translations.Fallback()
+
+	return formatMessage(locale, validationMessage(locale, e.Rule), attributeName(locale, e.Key), e.Params)
 }
 
 /*
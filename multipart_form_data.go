@@ -1,5 +1,12 @@
 package govel
 
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
 /*
  * Private methods
  */
@@ -20,12 +27,76 @@ func (f *MultipartFormData) getVariableForValidation() interface{} {
 	return f.validationVar
 }
 
+func (f *MultipartFormData) hasField(key string) bool {
+	if _, exists := f.request.Form[key]; exists {
+		return true
+	}
+
+	if f.request.MultipartForm == nil {
+		return false
+	}
+
+	_, exists := f.request.MultipartForm.File[key]
+
+	return exists
+}
+
+func (f *MultipartFormData) locale() string {
+	return requestLocale(f.request)
+}
+
+// fields returns the values and the files as nested maps, the files of a key are always a list.
+func (f *MultipartFormData) fields() map[string]interface{} {
+	if f.tree == nil {
+		f.tree = valuesTree(f.request.Form)
+
+		if f.request.MultipartForm != nil {
+			for key, headers := range f.request.MultipartForm.File {
+				for _, header := range headers {
+					insertNested(f.tree, splitFormKey(key), header, true)
+				}
+			}
+		}
+	}
+
+	return f.tree
+}
+
+// fileHeaders returns the files of a key, "photos" and "photos[]", or of a nested path like "photos.0" or "documents.passport".
+func (f *MultipartFormData) fileHeaders(key string) []*multipart.FileHeader {
+	if f.request.MultipartForm != nil {
+		if headers, exists := f.request.MultipartForm.File[key]; exists {
+			return headers
+		}
+
+		if headers, exists := f.request.MultipartForm.File[key+"[]"]; exists {
+			return headers
+		}
+	}
+
+	var headers []*multipart.FileHeader
+
+	node, _ := lookupField(f.fields(), splitFormKey(key))
+
+	for _, item := range toSlice(node) {
+		if header, isFile := item.(*multipart.FileHeader); isFile {
+			headers = append(headers, header)
+		}
+	}
+
+	return headers
+}
+
 /*
  * Public methods
  */
 
 // Gets all POST data of the form except files
 func (f *MultipartFormData) GetAll() interface{} {
+	if f.request.MultipartForm == nil {
+		return map[string][]string{}
+	}
+
 	return f.request.MultipartForm.Value
 }
 
@@ -35,41 +106,65 @@ func (f *MultipartFormData) Get(value string) string {
 }
 
 // Gets the first file associated with the given key.
+//
+// The key can be the path of a file in a list, e.g. "photos.0" for the first file of "photos[]".
+// The file is open, the caller must close it.
 func (f *MultipartFormData) GetFile(value string) (FormFile, error) {
 	file, header, err := f.request.FormFile(value)
 
+	if errors.Is(err, http.ErrMissingFile) && (strings.Contains(value, ".") || strings.Contains(value, "[")) {
+		if headers := f.fileHeaders(value); len(headers) > 0 {
+			header = headers[0]
+			file, err = header.Open()
+		}
+	}
+
 	return FormFile{File: file, FileHeader: header}, err
 }
 
-func (f *MultipartFormData) Validate(rules Map, onError OnError) (data map[string]interface{}, errors map[string]string) {
-	// All the valid validation rules
-	formRules := map[string]validateFunc{
-		"required":     validatorRequiredField,
-		"int":          validatorInt,
-		"string":       validatorString,
-		"min":          validatorMin,
-		"max":          validatorMax,
-		"email":        validatorEmail,
-		"optional":     validatorOptionalField,
-		"optionalFile": validatorOptionalFile,
-		"requiredFile": validatorRequiredFile,
-		"contentType":  validatorContentType,
-		"url":          validatorUrl,
-		"maxBytes":     validatorMaxBytes,
-		"date":         validatorDate,
-		"true":         validatorTrue,
-		"boolean":      validatorBoolean,
-		"confirm":      validatorCofirm,
-		"alpha_num":    validatorAlphaNum,
+// GetFiles returns all the files associated with the given key, "photos" or "photos[]".
+//
+// The files are open, the caller must close them. The files that cannot be opened are left out.
+func (f *MultipartFormData) GetFiles(key string) []FormFile {
+	var files []FormFile
+
+	for _, header := range f.fileHeaders(key) {
+		file, err := header.Open()
+
+		if err != nil {
+			continue
+		}
+
+		files = append(files, FormFile{File: file, FileHeader: header})
 	}
 
+	return files
+}
+
+func (f *MultipartFormData) Validate(rules Map, onError OnError) (data Map, errors map[string]string) {
 	// start validation
 	data = make(map[string]interface{})
-	errors = make(map[string]string)
+	validationErrors := &ValidationErrors{}
+
+	formValidator(data, validationErrors, rules, onError, f, true)
+
+	if validationErrors.Len() > 0 {
+		return nil, validationErrors.Map()
+	}
+
+	return data, nil
+}
+
+// ValidateAll validates a form like Validate, but returns every error of every field in order.
+//
+// Add the "bail" rule to a field to stop at its first error.
+func (f *MultipartFormData) ValidateAll(rules Map, onError OnError) (data Map, errors *ValidationErrors) {
+	data = make(map[string]interface{})
+	errors = &ValidationErrors{}
 
-	formValidator(formRules, data, errors, rules, onError, f)
+	formValidator(data, errors, rules, onError, f, false)
 
-	if len(errors) > 0 {
+	if errors.Len() > 0 {
 		return nil, errors
 	}
 
@@ -0,0 +1,52 @@
+package govel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMultipartFormDataFiles(t *testing.T) {
+	form := newTestMultipartForm(t, map[string]string{"title": "x"}, map[string][]testFile{
+		"photos[]": {{"a.png", []byte("a")}, {"b.png", []byte("b")}},
+		"avatar":   {{"me.png", []byte("me")}},
+	})
+
+	files := form.GetFiles("photos")
+
+	if len(files) != 2 || files[0].FileHeader.Filename != "a.png" || files[1].FileHeader.Filename != "b.png" {
+		t.Fatalf("GetFiles = %v", files)
+	}
+
+	for _, file := range files {
+		file.File.Close()
+	}
+
+	for key, name := range map[string]string{"avatar": "me.png", "photos.1": "b.png", "photos[1]": "b.png"} {
+		file, err := form.GetFile(key)
+
+		if err != nil || file.FileHeader.Filename != name {
+			t.Errorf("GetFile(%q) = %v, %v, want %s", key, file.FileHeader, err, name)
+			continue
+		}
+
+		file.File.Close()
+	}
+
+	if _, err := form.GetFile("missing"); err == nil {
+		t.Error("GetFile of a missing key did not fail")
+	}
+}
+
+func TestMultipartFormDataWithoutForm(t *testing.T) {
+	form := &MultipartFormData{request: httptest.NewRequest(http.MethodPost, "/", nil)}
+
+	if all := form.GetAll(); !reflect.DeepEqual(all, map[string][]string{}) {
+		t.Errorf("GetAll = %v", all)
+	}
+
+	if form.hasField("a") || len(form.fileHeaders("a")) > 0 || len(form.GetFiles("a")) > 0 {
+		t.Error("a form without a multipart body has fields")
+	}
+}
@@ -0,0 +1,215 @@
+/*
+ * This file contains the content negotiation and the response formats other than JSON and text.
+ */
+package govel
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// a valid JSONP callback, e.g. "callback" or "jQuery123.handle"
+var jsonpCallbackRegex = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*(\.[a-zA-Z_$][a-zA-Z0-9_$]*)*$`)
+
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept parses an "Accept" header.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+
+		if mediaType == "" {
+			continue
+		}
+
+		quality := 1.0
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = value
+				}
+			}
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+
+	return ranges
+}
+
+// mediaTypeQuality returns the quality of the most specific range that matches the media type.
+func mediaTypeQuality(ranges []acceptRange, mediaType string) float64 {
+	mediaType = strings.ToLower(mediaType)
+	mainType := strings.Split(mediaType, "/")[0]
+
+	quality := 0.0
+	specificity := -1
+
+	for _, r := range ranges {
+		s := -1
+
+		switch {
+		case r.mediaType == mediaType:
+			s = 2
+
+		case r.mediaType == mainType+"/*":
+			s = 1
+
+		case r.mediaType == "*/*":
+			s = 0
+		}
+
+		if s > specificity {
+			specificity = s
+			quality = r.quality
+		}
+	}
+
+	return quality
+}
+
+// negotiate returns the offer with the highest quality in the "Accept" header, the first one wins a tie.
+//
+// It returns "" if none of the offers is acceptable.
+func negotiate(header string, offers ...string) string {
+	if strings.TrimSpace(header) == "" {
+		if len(offers) > 0 {
+			return offers[0]
+		}
+
+		return ""
+	}
+
+	ranges := parseAccept(header)
+
+	best := ""
+	bestQuality := 0.0
+
+	for _, offer := range offers {
+		quality := mediaTypeQuality(ranges, offer)
+
+		if quality > bestQuality {
+			best = offer
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// Accepts returns the media type of the offers that the client prefers, based on the "Accept" header and its q-values.
+//
+// If several offers have the same quality the first one is returned. It returns "" if none is acceptable.
+func (c *Context) Accepts(offers ...string) string {
+	return negotiate(c.GetHeader("Accept"), offers...)
+}
+
+// Negotiate calls the function of the media type that the client prefers, after setting the status code.
+//
+//	c.Negotiate(200, map[string]func(){
+//		"application/json": func() { c.RawJson(user) },
+//		"text/html":        func() { c.View(200, "users/show", user) },
+//	})
+//
+// Ties are resolved in alphabetical order of the media types.
+// It returns a 406 Not Acceptable error if none of the media types is acceptable.
+func (c *Context) Negotiate(statusCode int, offers map[string]func()) error {
+	mediaTypes := make([]string, 0, len(offers))
+
+	for mediaType := range offers {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+
+	sort.Strings(mediaTypes)
+
+	best := c.Accepts(mediaTypes...)
+
+	if best == "" {
+		return NewHTTPError(http.StatusNotAcceptable)
+	}
+
+	c.Status(statusCode)
+	c.addVary("Accept")
+
+	offers[best]()
+
+	return nil
+}
+
+// PrettyJson is the same as Json but the JSON is indented.
+func (c *Context) PrettyJson(s int, j interface{}) error {
+	c.ContentType("application/json")
+	c.Status(s)
+
+	encoder := json.NewEncoder(c.Buf)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(j)
+}
+
+// JSONP sends JSON wrapped in a call to callback.
+//
+// An invalid callback name returns a 400 Bad Request error.
+func (c *Context) JSONP(statusCode int, callback string, j interface{}) error {
+	if !jsonpCallbackRegex.MatchString(callback) {
+		return BadRequest("Invalid JSONP callback")
+	}
+
+	content, err := json.Marshal(j)
+
+	if err != nil {
+		return err
+	}
+
+	c.ContentType("application/javascript; charset=utf-8")
+	c.Headers["X-Content-Type-Options"] = "nosniff"
+	c.Status(statusCode)
+
+	// the comment prevents the Rosetta Flash attack
+	fmt.Fprintf(c.Buf, "/**/ typeof %s === 'function' && %s(%s);", callback, callback, content)
+
+	return nil
+}
+
+// XML sends v encoded as XML.
+func (c *Context) XML(statusCode int, v interface{}) error {
+	c.ContentType("application/xml; charset=utf-8")
+	c.Status(statusCode)
+
+	c.Buf.WriteString(xml.Header)
+
+	return xml.NewEncoder(c.Buf).Encode(v)
+}
+
+// YAML sends v encoded as YAML.
+func (c *Context) YAML(statusCode int, v interface{}) error {
+	content, err := yaml.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	c.ContentType("application/yaml; charset=utf-8")
+	c.Status(statusCode)
+
+	c.Buf.Write(content)
+
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+ * This file contains the RFC 7807 "problem details" responses.
+ */
+package govel
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	problemContentType = "application/problem+json"
+
+	validationFailedMessage = "The given data was invalid."
+)
+
+// Problem is an RFC 7807 problem details object.
+//
+// See https://www.rfc-editor.org/rfc/rfc7807.
+type Problem struct {
+	// Type is a URI that identifies the problem type, it defaults to "about:blank".
+	Type string
+
+	// Title is a short summary of the problem type.
+	Title string
+
+	// Status is the HTTP status code.
+	Status int
+
+	// Detail is an explanation specific to this occurrence of the problem.
+	Detail string
+
+	// Instance is a URI that identifies this occurrence of the problem.
+	Instance string
+
+	// Extensions are additional members of the problem object.
+	Extensions Map
+}
+
+// NewProblem creates a Problem with the status text as title.
+func NewProblem(status int, detail string) Problem {
+	return Problem{Status: status, Title: http.StatusText(status), Detail: detail}
+}
+
+// ValidationProblem creates a 422 Problem with the validation errors in the "errors" member.
+func ValidationProblem(errors map[string]string) Problem {
+	return ValidationFailed(errors).Problem()
+}
+
+// MarshalJSON flattens the extensions into the problem object.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{})
+
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	members["type"] = p.Type
+
+	if p.Type == "" {
+		members["type"] = "about:blank"
+	}
+
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+// Problem converts the HTTPError to a Problem.
+//
+// map[string]string details are sent in the "errors" member, any other details in the "details" member.
+func (e HTTPError) Problem() Problem {
+	p := NewProblem(e.Status, "")
+
+	if e.Message != "" && e.Message != p.Title {
+		p.Detail = e.Message
+	}
+
+	switch details := e.Details.(type) {
+	case nil:
+
+	case map[string]string:
+		p.Extensions = Map{"errors": details}
+
+	case SMap:
+		p.Extensions = Map{"errors": details}
+
+	case *ValidationErrors:
+		p.Extensions = Map{"errors": details}
+
+	default:
+		p.Extensions = Map{"details": details}
+	}
+
+	return p
+}
+
+// Problem sends an "application/problem+json" response.
+func (c *Context) Problem(p Problem) error {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+
+	c.ContentType(problemContentType)
+	c.Status(p.Status)
+
+	return json.NewEncoder(c.Buf).Encode(p)
+}
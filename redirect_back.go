@@ -0,0 +1,125 @@
+/*
+ * This file contains the redirects to the previous page with the validation errors and the old input.
+ */
+package govel
+
+import (
+	"encoding/gob"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	// the errors and the old input are stored in the session as map[string]string
+	gob.Register(map[string]string{})
+}
+
+// StorePreviousURL is a middleware that saves the URL of the GET requests for pages in the session,
+// RedirectBack uses it when the request has no Referer.
+func StorePreviousURL(c *Context) int {
+	if c.Request.Method != http.MethodGet || wantsJSON(c.Request) {
+		return ContinueRequest
+	}
+
+	if session, err := c.frameworkSession(); err == nil {
+		session.Set(previousURLSessionKey, c.Request.URL.RequestURI())
+	}
+
+	return ContinueRequest
+}
+
+// RedirectBack redirects the user to the previous page with a 302 Found.
+//
+// The previous page is the Referer if it is on the same host, the URL saved by StorePreviousURL or "/".
+func (c *Context) RedirectBack() {
+	c.Redirect(c.previousURL(), http.StatusFound)
+}
+
+// RedirectBackWithErrors redirects the user to the previous page with the validation errors and the submitted input,
+// which are available in the next request with Errors and Old.
+//
+// The fields whose name contains "password" and the CSRF token are not saved. Sessions must be configured.
+func (c *Context) RedirectBackWithErrors(errors map[string]string) error {
+	err := c.SetFlash(errorsFlashKey, errors)
+
+	if err != nil {
+		return err
+	}
+
+	err = c.SetFlash(oldInputFlashKey, c.oldInput())
+
+	if err != nil {
+		return err
+	}
+
+	c.RedirectBack()
+
+	return nil
+}
+
+// Errors returns the validation errors saved by RedirectBackWithErrors in the previous request.
+func (c *Context) Errors() map[string]string {
+	errors, _ := c.Flash(errorsFlashKey).(map[string]string)
+
+	return errors
+}
+
+// Old returns the value of a field submitted in the previous request, saved by RedirectBackWithErrors, or fallback.
+//
+// Nested fields can use dots, "items.0.name" is the same as "items[0][name]".
+func (c *Context) Old(key string, fallback ...string) string {
+	old, _ := c.Flash(oldInputFlashKey).(map[string]string)
+
+	if value, exists := old[key]; exists {
+		return value
+	}
+
+	if parts := strings.Split(key, "."); len(parts) > 1 {
+		if value, exists := old[parts[0]+"["+strings.Join(parts[1:], "][")+"]"]; exists {
+			return value
+		}
+	}
+
+	if len(fallback) > 0 {
+		return fallback[0]
+	}
+
+	return ""
+}
+
+// oldInput returns the first value of each field of the request, except the passwords and the CSRF token.
+func (c *Context) oldInput() map[string]string {
+	old := make(map[string]string)
+
+	if c.Request.Form == nil {
+		c.Request.ParseForm()
+	}
+
+	for key, values := range c.Request.Form {
+		if key == CSRFFieldName || strings.Contains(strings.ToLower(key), "password") || len(values) == 0 {
+			continue
+		}
+
+		old[key] = values[0]
+	}
+
+	return old
+}
+
+// previousURL returns the Referer if it is on the same host, the URL saved by StorePreviousURL or "/".
+func (c *Context) previousURL() string {
+	if referer, err := url.Parse(c.GetHeader("Referer")); err == nil && referer.Path != "" {
+		if referer.Host == "" || referer.Host == c.Request.Host {
+			return referer.RequestURI()
+		}
+	}
+
+	if session, err := c.frameworkSession(); err == nil {
+		if previous, _ := session.Get(previousURLSessionKey).(string); previous != "" {
+			return previous
+		}
+	}
+
+	return "/"
+}
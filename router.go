@@ -25,6 +25,12 @@ var (
 	// A global "Panic handler" function.
 	panicHandlerFunc panicHandler
 
+	// Renders the errors returned by the actions.
+	errorRendererFunc errorRenderer = defaultErrorRenderer
+
+	// Receives every unexpected error and panic, nil if not set.
+	errorReporterFunc errorReporter
+
 	// modules to be initialized
 	modules []initModuleFunc
 
@@ -37,6 +43,12 @@ var (
 	// Global middlewares
 	globalMiddlewares middlewaresFunctions
 
+	// Indicates if the developer error page must be shown on panics.
+	debugMode bool
+
+	// The url prefix of the static files, used by the "asset" view function.
+	staticPath string
+
 	// indicates if the current route is inside a group
 	inGroup            bool
 	currentGroupConfig = &groupModel{routes: make(map[string]*routeModel)}
@@ -63,7 +75,7 @@ func LoadConfigFIle(file string) {
 
 		router.
 			Path(m.path).
-			Handler(callFunction(m.action, m.middlewares)).
+			Handler(callFunction(m)).
 			Methods(m.method)
 
 		newRoute := routeNamed{Route: m.name, Url: m.path}
@@ -103,15 +115,39 @@ func readYamlAndGetPort(file string) string {
 		panic("The port is required.")
 	}
 
+	debugMode = yamlConfig.Debug
+
+	sqlConfig = yamlConfig.Sql
+
 	configFileKeys = make(map[interface{}]interface{})
 
 	yaml.Unmarshal(fileContent, configFileKeys)
 
 	// set the rest of the configuraiton
 	if yamlConfig.Static.Dir != "" && yamlConfig.Static.Path != "" {
-		s := http.StripPrefix(yamlConfig.Static.Path, http.FileServer(http.Dir(yamlConfig.Static.Dir+"/")))
+		s := http.StripPrefix(yamlConfig.Static.Path, staticFileServer(yamlConfig.Static.Dir+"/"))
 
 		router.PathPrefix(yamlConfig.Static.Path).Handler(s)
+
+		staticPath = yamlConfig.Static.Path
+	}
+
+	if yamlConfig.Views.Dir != "" {
+		viewsDir = yamlConfig.Views.Dir
+	}
+
+	if yamlConfig.Views.Extension != "" {
+		viewsExtension = yamlConfig.Views.Extension
+	}
+
+	if yamlConfig.I18n.Locale != "" {
+		Translations.SetFallback(yamlConfig.I18n.Locale)
+	}
+
+	if yamlConfig.I18n.Dir != "" {
+		err := Translations.LoadDir(yamlConfig.I18n.Dir)
+
+		getErr(err)
 	}
 
 	if yamlConfig.Keys.Sessions != "" {
@@ -141,33 +177,33 @@ func readYamlAndGetPort(file string) string {
 
 // functions
 
-func Get(path string, action routeFunction) *routeModel {
+func Get[A Action](path string, action A) *routeModel {
 
-	m := routeModel{unique_id: time.Now().String(), path: path, action: action, method: "GET"}
+	m := routeModel{unique_id: time.Now().String(), path: path, action: toRouteFunction(action), actionName: functionName(action), method: "GET"}
 	m.update()
 
 	return &m
 }
 
-func Post(path string, action routeFunction) *routeModel {
+func Post[A Action](path string, action A) *routeModel {
 
-	m := routeModel{unique_id: time.Now().String(), path: path, action: action, method: "POST"}
+	m := routeModel{unique_id: time.Now().String(), path: path, action: toRouteFunction(action), actionName: functionName(action), method: "POST"}
 	m.update()
 
 	return &m
 }
 
-func Put(path string, action routeFunction) *routeModel {
+func Put[A Action](path string, action A) *routeModel {
 
-	m := routeModel{unique_id: time.Now().String(), path: path, action: action, method: "PUT"}
+	m := routeModel{unique_id: time.Now().String(), path: path, action: toRouteFunction(action), actionName: functionName(action), method: "PUT"}
 	m.update()
 
 	return &m
 }
 
-func Delete(path string, action routeFunction) *routeModel {
+func Delete[A Action](path string, action A) *routeModel {
 
-	m := routeModel{unique_id: time.Now().String(), path: path, action: action, method: "DELETE"}
+	m := routeModel{unique_id: time.Now().String(), path: path, action: toRouteFunction(action), actionName: functionName(action), method: "DELETE"}
 	m.update()
 
 	return &m
@@ -182,6 +218,22 @@ func (m *routeModel) Middlewares(action ...middlewareFunction) *routeModel {
 	return m
 }
 
+// Validate validates the request with a FormRequest before the action runs.
+//
+// If the validation fails, API and JSON requests get a 422 Unprocessable Entity error with every message,
+// other requests are redirected back with the errors and the old input. The action gets the data with Context.Validated.
+//
+//	govel.Post("/users", createUser).Validate(CreateUserRequest{})
+func (m *routeModel) Validate(request FormRequest) *routeModel {
+	checkPresenceRules(request.Rules())
+
+	m.formRequest = request
+
+	m.update()
+
+	return m
+}
+
 // Name adds a name to a route.
 func (m *routeModel) Name(name string) *routeModel {
 	m.name = name
@@ -191,6 +243,15 @@ func (m *routeModel) Name(name string) *routeModel {
 	return m
 }
 
+// API marks the route as an API route, its errors are rendered as "application/problem+json".
+func (m *routeModel) API() *routeModel {
+	m.api = true
+
+	m.update()
+
+	return m
+}
+
 // Saves or updates the route.
 func (m *routeModel) update() {
 
@@ -268,20 +329,36 @@ func GetKeyFromYAML(key string) interface{} {
 }
 
 // Sets a "404 url not found" function.
-func Set404NotFound(function routeFunction) {
-	router.NotFoundHandler = httpHandler(function)
+func Set404NotFound[A Action](function A) {
+	router.NotFoundHandler = httpHandler(toRouteFunction(function))
 }
 
 // Sets a general "method not allowed" function.
-func SetMethodNotAllowed(function routeFunction) {
-	router.MethodNotAllowedHandler = httpHandler(function)
+func SetMethodNotAllowed[A Action](function A) {
+	router.MethodNotAllowedHandler = httpHandler(toRouteFunction(function))
 }
 
 // Sets a general "handle panic" function.
+//
+// Anything written before the panic is discarded and the status code is set to 500 before the function is called.
 func SetPanicHandler(function panicHandler) {
 	panicHandlerFunc = function
 }
 
+// SetErrorRenderer sets the function that writes the response for the errors returned by the actions.
+//
+// Errors that are not an HTTPError are rendered as a 500 Internal Server Error.
+func SetErrorRenderer(function errorRenderer) {
+	errorRendererFunc = function
+}
+
+// SetErrorReporter sets a function that receives every unexpected error and panic, for example to send them to an error tracker.
+//
+// HTTPErrors are only reported if their status code is 500 or greater.
+func SetErrorReporter(function errorReporter) {
+	errorReporterFunc = function
+}
+
 // Sets global middlewares.
 func SetGlobalMiddlewares(function ...middlewareFunction) {
 	globalMiddlewares = function
@@ -0,0 +1,174 @@
+/*
+ * This file contains the registry of the validation rules.
+ */
+package govel
+
+import (
+	"fmt"
+)
+
+// the rules available for every type of form.
+var validationRules = map[string]RuleFunc{
+	"required":  builtinRule(validatorRequiredField),
+	"int":       builtinRule(validatorInt),
+	"string":    builtinRule(validatorString),
+	"min":       builtinRule(validatorMin),
+	"max":       builtinRule(validatorMax),
+	"email":     builtinRule(validatorEmail),
+	"optional":  builtinRule(validatorOptionalField),
+	"url":       builtinRule(validatorUrl),
+	"date":      builtinRule(validatorDate),
+	"true":      builtinRule(validatorTrue),
+	"boolean":   builtinRule(validatorBoolean),
+	"confirm":   builtinRule(validatorCofirm),
+	"alpha_num": builtinRule(validatorAlphaNum),
+
+	"numeric":     builtinRule(validatorNumeric),
+	"float":       builtinRule(validatorNumeric),
+	"between":     builtinRule(validatorBetween),
+	"in":          builtinRule(validatorIn),
+	"not_in":      builtinRule(validatorNotIn),
+	"regex":       builtinRule(validatorRegex),
+	"uuid":        builtinRule(validatorUUID),
+	"ip":          builtinRule(validatorIP),
+	"ipv4":        builtinRule(validatorIPv4),
+	"ipv6":        builtinRule(validatorIPv6),
+	"mac_address": builtinRule(validatorMacAddress),
+	"json":        builtinRule(validatorJSON),
+	"timezone":    builtinRule(validatorTimezone),
+	"alpha":       builtinRule(validatorAlpha),
+	"alpha_dash":  builtinRule(validatorAlphaDash),
+	"digits":      builtinRule(validatorDigits),
+	"starts_with": builtinRule(validatorStartsWith),
+	"ends_with":   builtinRule(validatorEndsWith),
+	"lowercase":   builtinRule(validatorLowercase),
+	"uppercase":   builtinRule(validatorUppercase),
+	"same":        builtinRule(validatorSame),
+	"different":   builtinRule(validatorDifferent),
+	"before":      builtinRule(validatorBefore),
+	"after":       builtinRule(validatorAfter),
+
+	"required_if":      builtinRule(validatorRequiredIf),
+	"required_unless":  builtinRule(validatorRequiredUnless),
+	"required_with":    builtinRule(validatorRequiredWith),
+	"required_without": builtinRule(validatorRequiredWithout),
+	"prohibited_if":    builtinRule(validatorProhibitedIf),
+	"exclude_if":       builtinRule(validatorExcludeIf),
+	"sometimes":        builtinRule(validatorSometimes),
+	"array":            builtinRule(validatorArray),
+	"bail":             builtinRule(validatorBail),
+	"unique":           builtinRule(validatorUnique),
+	"exists":           builtinRule(validatorExists),
+}
+
+// the rules only available for MultipartFormData.
+var fileValidationRules = map[string]RuleFunc{
+	"optionalFile": builtinRule(validatorOptionalFile),
+	"requiredFile": builtinRule(validatorRequiredFile),
+	"contentType":  builtinRule(validatorContentType),
+	"maxBytes":     builtinRule(validatorMaxBytes),
+
+	"minFiles":      builtinRule(validatorMinFiles),
+	"maxFiles":      builtinRule(validatorMaxFiles),
+	"maxTotalBytes": builtinRule(validatorMaxTotalBytes),
+	"mimes":         builtinRule(validatorMimes),
+	"image":         builtinRule(validatorImage),
+	"dimensions":    builtinRule(validatorDimensions),
+}
+
+// RegisterRule adds a validation rule for every type of form, or replaces an existing one.
+//
+// The rule is used like the built-in rules, e.g. "required|uppercase" or "divisible_by:3":
+//
+//	govel.RegisterRule("divisible_by", func(ctx govel.RuleContext) error {
+//		n, _ := strconv.Atoi(ctx.Value())
+//		d, _ := strconv.Atoi(ctx.Param())
+//
+//		if n%d != 0 {
+//			return fmt.Errorf("%s must be divisible by %d", ctx.Key(), d)
+//		}
+//
+//		return nil
+//	})
+//
+// It must be called before the server starts.
+func RegisterRule(name string, rule RuleFunc) {
+	if !ruleNameRegex.MatchString(name) {
+		panic(fmt.Sprintf("Rule name %s not valid, only letters and underscores are allowed", name))
+	}
+
+	validationRules[name] = rule
+}
+
+// findRule returns the rule with the given name for the form.
+func findRule(name string, f formInterface) (RuleFunc, bool) {
+	rule, exists := validationRules[name]
+
+	if exists {
+		return rule, true
+	}
+
+	if _, isMultipart := f.(*MultipartFormData); isMultipart {
+		rule, exists = fileValidationRules[name]
+	}
+
+	return rule, exists
+}
+
+// builtinRule adapts a validateFunc, which receives the parameter of the rule instead of the value when there is one.
+func builtinRule(validation validateFunc) RuleFunc {
+	return func(ctx RuleContext) error {
+		c := ctx.(*ruleContext)
+
+		if c.hasParam {
+			return validation(c.key, c.param, c.form)
+		}
+
+		return validation(c.key, c.value, c.form)
+	}
+}
+
+// ruleContext is the RuleContext passed to the rules.
+type ruleContext struct {
+	key      string
+	value    string
+	param    string
+	hasParam bool
+	form     formInterface
+}
+
+func (c *ruleContext) Key() string {
+	return c.key
+}
+
+func (c *ruleContext) Value() string {
+	return c.value
+}
+
+func (c *ruleContext) Param() string {
+	return c.param
+}
+
+func (c *ruleContext) Params() []string {
+	if c.param == "" {
+		return nil
+	}
+
+	return splitParams(c.param)
+}
+
+func (c *ruleContext) Form() FormValues {
+	return c.form
+}
+
+func (c *ruleContext) Current() interface{} {
+	return c.form.getVariableForValidation()
+}
+
+func (c *ruleContext) SetCurrent(v interface{}) {
+	c.form.setVariableForValidation(v)
+}
+
+func (c *ruleContext) Skip() {
+	c.form.setSkipValidation(true)
+}
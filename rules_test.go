@@ -0,0 +1,110 @@
+package govel
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// registerTestRule registers a rule for the duration of the test.
+func registerTestRule(t *testing.T, name string, rule RuleFunc) {
+	t.Helper()
+
+	previous, existed := validationRules[name]
+
+	RegisterRule(name, rule)
+
+	t.Cleanup(func() {
+		if existed {
+			validationRules[name] = previous
+		} else {
+			delete(validationRules, name)
+		}
+	})
+}
+
+func TestRegisterRule(t *testing.T) {
+	var calls []RuleContext
+
+	registerTestRule(t, "divisible_by", func(ctx RuleContext) error {
+		calls = append(calls, ctx)
+
+		n, _ := strconv.Atoi(ctx.Value())
+		d, _ := strconv.Atoi(ctx.Param())
+
+		if n%d != 0 {
+			return errors.New(":attribute must be divisible by " + ctx.Param())
+		}
+
+		ctx.SetCurrent(n / d)
+
+		return nil
+	})
+
+	form := newTestForm(t, "a=9&b=10")
+
+	data, errs := form.ValidateAll(Map{"a": "required|divisible_by:3", "b": "divisible_by:3"}, nil)
+
+	if data != nil || errs.First("b") != "b must be divisible by 3" || errs.Has("a") {
+		t.Fatalf("data = %v, errors = %v", data, errs)
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("the rule was called %d times, want 2", len(calls))
+	}
+
+	ctx := calls[0]
+
+	if ctx.Key() != "a" || ctx.Value() != "9" || ctx.Param() != "3" || !reflect.DeepEqual(ctx.Params(), []string{"3"}) || ctx.Form().Get("b") != "10" {
+		t.Errorf("context = %q %q %q %v", ctx.Key(), ctx.Value(), ctx.Param(), ctx.Params())
+	}
+
+	data, _ = form.ValidateAll(Map{"a": "divisible_by:3"}, nil)
+
+	if data["a"] != 3 {
+		t.Errorf("SetCurrent: data = %v, want a = 3", data)
+	}
+
+	_, messages := form.Validate(Map{"b": "divisible_by:3"}, OnError{"b": {"divisible_by": "custom :attribute"}})
+
+	if messages["b"] != "custom b" {
+		t.Errorf("OnError: %v", messages)
+	}
+}
+
+func TestRegisterRuleSkip(t *testing.T) {
+	registerTestRule(t, "skip_empty", func(ctx RuleContext) error {
+		if ctx.Value() == "" {
+			ctx.Skip()
+		}
+
+		return nil
+	})
+
+	data, errs := newTestForm(t, "a=&b=x").ValidateAll(Map{"a": "skip_empty|required", "b": "skip_empty|alpha"}, nil)
+
+	if errs != nil || !reflect.DeepEqual(data, Map{"b": "x"}) {
+		t.Errorf("data = %v, errors = %v", data, errs)
+	}
+}
+
+func TestRegisterRuleReplacesBuiltin(t *testing.T) {
+	registerTestRule(t, "alpha", func(ctx RuleContext) error {
+		return nil
+	})
+
+	if _, errs := newTestForm(t, "a=1").ValidateAll(Map{"a": "alpha"}, nil); errs != nil {
+		t.Errorf("the built-in rule was not replaced: %v", errs)
+	}
+}
+
+func TestRegisterRuleInvalidName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterRule did not panic")
+		}
+	}()
+
+	RegisterRule("not-valid", func(ctx RuleContext) error { return nil })
+}
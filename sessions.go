@@ -1,6 +1,7 @@
 package govel
 
 import (
+	"errors"
 	"net/http"
 )
 
@@ -71,3 +72,54 @@ func (s *Session) Secure(secure bool) {
 func (s *Session) SameSite(sameSite http.SameSite) {
 	s.session.Options.SameSite = sameSite
 }
+
+// frameworkSession returns the session used by the framework for the flash data and the CSRF token.
+func (c *Context) frameworkSession() (Session, error) {
+	if Store == nil {
+		return Session{}, errors.New("govel: sessions are not configured, set keys.sessions in the .yaml file")
+	}
+
+	for _, session := range c.sessions {
+		if session.session.Name() == frameworkSessionName {
+			return session, nil
+		}
+	}
+
+	return c.Session(frameworkSessionName)
+}
+
+// Flash returns a flash value of the framework session.
+//
+// Unlike Session.GetFlash, the value can be read any number of times during the request.
+func (c *Context) Flash(key string) interface{} {
+	if value, exists := c.flashes[key]; exists {
+		return value
+	}
+
+	session, err := c.frameworkSession()
+
+	if err != nil {
+		return nil
+	}
+
+	if c.flashes == nil {
+		c.flashes = make(map[string]interface{})
+	}
+
+	c.flashes[key] = session.GetFlash(key)
+
+	return c.flashes[key]
+}
+
+// SetFlash sets a flash value in the framework session, it will be available in the next request.
+func (c *Context) SetFlash(key string, value interface{}) error {
+	session, err := c.frameworkSession()
+
+	if err != nil {
+		return err
+	}
+
+	session.SetFlash(key, value)
+
+	return nil
+}
@@ -0,0 +1,336 @@
+/*
+ * This file contains the Server-Sent Events responses and the topic broadcaster.
+ */
+package govel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEHeartbeatInterval is the time between the keepalive comments sent to idle SSE connections.
+var SSEHeartbeatInterval = 15 * time.Second
+
+// Event is a Server-Sent Event.
+type Event struct {
+	// ID is sent as the "id" field, the browser sends it back in the "Last-Event-ID" header when reconnecting.
+	ID string
+
+	// Name is sent as the "event" field, if empty the browser dispatches a "message" event.
+	Name string
+
+	// Data is sent as is if it is a string or a []byte, otherwise it is encoded as JSON.
+	Data interface{}
+
+	// Retry tells the browser how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// EventStream is an open Server-Sent Events connection.
+type EventStream struct {
+	c *Context
+
+	w *streamWriter
+
+	mu sync.Mutex
+
+	closed bool
+}
+
+// SSE opens a Server-Sent Events stream and calls function with it.
+//
+// The connection is closed when function returns.
+// Use Done to know when the client disconnects.
+func (c *Context) SSE(function func(stream *EventStream)) error {
+	c.Headers["Cache-Control"] = "no-cache"
+	c.Headers["Connection"] = "keep-alive"
+
+	// disable the response buffering of nginx
+	c.Headers["X-Accel-Buffering"] = "no"
+
+	return c.Stream(http.StatusOK, "text/event-stream", func(w io.Writer) error {
+		stream := &EventStream{c: c, w: w.(*streamWriter)}
+
+		stopHeartbeat := make(chan struct{})
+		defer close(stopHeartbeat)
+
+		// close the stream even if function panics, so a running write cannot reach the response afterwards
+		defer func() {
+			stream.mu.Lock()
+			stream.closed = true
+			stream.mu.Unlock()
+		}()
+
+		go stream.heartbeat(stopHeartbeat)
+
+		function(stream)
+
+		return nil
+	})
+}
+
+// heartbeat sends a comment every SSEHeartbeatInterval so proxies do not close the connection.
+func (s *EventStream) heartbeat(stop chan struct{}) {
+	ticker := time.NewTicker(SSEHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.write(": keepalive\n\n")
+
+		case <-stop:
+			return
+
+		case <-s.Done():
+			return
+		}
+	}
+}
+
+// Done returns a channel that is closed when the client disconnects.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.c.Request.Context().Done()
+}
+
+// Context returns the Context of the request.
+func (s *EventStream) Context() *Context {
+	return s.c
+}
+
+// LastEventID returns the "Last-Event-ID" header sent by the browser when reconnecting.
+func (s *EventStream) LastEventID() string {
+	return s.c.GetHeader("Last-Event-ID")
+}
+
+// Send sends an event.
+func (s *EventStream) Send(event Event) error {
+	var builder strings.Builder
+
+	if event.ID != "" {
+		fmt.Fprintf(&builder, "id: %s\n", singleLine(event.ID))
+	}
+
+	if event.Name != "" {
+		fmt.Fprintf(&builder, "event: %s\n", singleLine(event.Name))
+	}
+
+	if event.Retry > 0 {
+		fmt.Fprintf(&builder, "retry: %d\n", event.Retry.Milliseconds())
+	}
+
+	if event.Data != nil {
+		var data string
+
+		switch value := event.Data.(type) {
+		case string:
+			data = value
+
+		case []byte:
+			data = string(value)
+
+		default:
+			encoded, err := json.Marshal(value)
+
+			if err != nil {
+				return err
+			}
+
+			data = string(encoded)
+		}
+
+		// every line of the data needs its own field
+		for _, line := range strings.Split(data, "\n") {
+			fmt.Fprintf(&builder, "data: %s\n", strings.TrimSuffix(line, "\r"))
+		}
+	}
+
+	builder.WriteString("\n")
+
+	return s.write(builder.String())
+}
+
+// Event sends a named event.
+func (s *EventStream) Event(name string, data interface{}) error {
+	return s.Send(Event{Name: name, Data: data})
+}
+
+// Data sends an unnamed event.
+func (s *EventStream) Data(data interface{}) error {
+	return s.Send(Event{Data: data})
+}
+
+// Retry tells the browser how long to wait before reconnecting.
+func (s *EventStream) Retry(retry time.Duration) error {
+	return s.write(fmt.Sprintf("retry: %d\n\n", retry.Milliseconds()))
+}
+
+// Subscribe sends every event published to the topics until the client disconnects.
+//
+// It blocks, so it is usually the last call in the SSE function.
+func (s *EventStream) Subscribe(broadcaster *Broadcaster, topics ...string) error {
+	subscription := broadcaster.Subscribe(topics...)
+	defer subscription.Close()
+
+	for {
+		select {
+		case event := <-subscription.Events():
+			if err := s.Send(event); err != nil {
+				return err
+			}
+
+		case <-s.Done():
+			return nil
+		}
+	}
+}
+
+// write sends raw data and flushes it.
+func (s *EventStream) write(data string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return io.ErrClosedPipe
+	}
+
+	_, err := io.WriteString(s.w, data)
+
+	if err != nil {
+		return err
+	}
+
+	s.w.Flush()
+
+	return nil
+}
+
+// singleLine removes the line breaks of a field.
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+/*
+ * Broadcaster
+ */
+
+// the BufferSize of a Broadcaster that does not set it.
+const defaultBroadcasterBufferSize = 16
+
+// Broadcaster sends the events published to a topic to all its subscribers.
+//
+// The zero value is ready to use.
+type Broadcaster struct {
+	mu sync.RWMutex
+
+	topics map[string]map[*Subscription]struct{}
+
+	// BufferSize is the number of events that a slow subscriber can have pending before new events are dropped.
+	//
+	// 0 uses 16.
+	BufferSize int
+}
+
+// Subscription receives the events of one or more topics.
+type Subscription struct {
+	broadcaster *Broadcaster
+
+	topics []string
+
+	events chan Event
+
+	once sync.Once
+}
+
+// NewBroadcaster creates a Broadcaster.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		topics:     make(map[string]map[*Subscription]struct{}),
+		BufferSize: defaultBroadcasterBufferSize,
+	}
+}
+
+// Subscribe creates a Subscription to the topics.
+//
+// Close must be called when the Subscription is no longer needed.
+func (b *Broadcaster) Subscribe(topics ...string) *Subscription {
+	bufferSize := b.BufferSize
+
+	if bufferSize <= 0 {
+		bufferSize = defaultBroadcasterBufferSize
+	}
+
+	subscription := &Subscription{
+		broadcaster: b,
+		topics:      topics,
+		events:      make(chan Event, bufferSize),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.topics == nil {
+		b.topics = make(map[string]map[*Subscription]struct{})
+	}
+
+	for _, topic := range topics {
+		if b.topics[topic] == nil {
+			b.topics[topic] = make(map[*Subscription]struct{})
+		}
+
+		b.topics[topic][subscription] = struct{}{}
+	}
+
+	return subscription
+}
+
+// Publish sends an event to every subscriber of the topic.
+//
+// It never blocks, subscribers whose buffer is full miss the event.
+func (b *Broadcaster) Publish(topic string, event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for subscription := range b.topics[topic] {
+		select {
+		case subscription.events <- event:
+		default:
+		}
+	}
+}
+
+// Subscribers returns the number of subscribers of a topic.
+func (b *Broadcaster) Subscribers(topic string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.topics[topic])
+}
+
+// Events returns the channel that receives the events.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unsubscribes from all the topics.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		b := s.broadcaster
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		for _, topic := range s.topics {
+			delete(b.topics[topic], s)
+
+			if len(b.topics[topic]) == 0 {
+				delete(b.topics, topic)
+			}
+		}
+	})
+}
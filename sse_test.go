@@ -0,0 +1,31 @@
+package govel
+
+import "testing"
+
+func TestBroadcasterZeroValue(t *testing.T) {
+	var b Broadcaster
+
+	if b.Subscribers("news") != 0 {
+		t.Fatal("an empty broadcaster has subscribers")
+	}
+
+	b.Publish("news", Event{Data: "nobody"})
+
+	subscription := b.Subscribe("news", "sports")
+
+	if b.Subscribers("news") != 1 || b.Subscribers("sports") != 1 {
+		t.Fatalf("subscribers = %d, %d", b.Subscribers("news"), b.Subscribers("sports"))
+	}
+
+	b.Publish("news", Event{Data: "hello"})
+
+	if event := <-subscription.Events(); event.Data != "hello" {
+		t.Errorf("event = %v", event)
+	}
+
+	subscription.Close()
+
+	if b.Subscribers("news") != 0 {
+		t.Error("Close did not unsubscribe")
+	}
+}
@@ -0,0 +1,58 @@
+package govel
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// staticFileServer serves the files of a directory.
+//
+// If the client accepts gzip and a precompressed "file.gz" exists next to the requested file, the compressed file is served instead.
+func staticFileServer(dir string) http.Handler {
+	root := http.Dir(dir)
+	fileServer := http.FileServer(root)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + r.URL.Path)
+		contentType := mime.TypeByExtension(path.Ext(name))
+
+		if strings.HasSuffix(r.URL.Path, "/") || contentType == "" || path.Ext(name) == ".gz" {
+			fileServer.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if encodingQuality(r.Header.Get("Accept-Encoding"), "gzip") <= 0 {
+			fileServer.ServeHTTP(w, r)
+
+			return
+		}
+
+		file, err := root.Open(name + ".gz")
+
+		if err != nil {
+			fileServer.ServeHTTP(w, r)
+
+			return
+		}
+
+		defer file.Close()
+
+		stat, err := file.Stat()
+
+		if err != nil || stat.IsDir() {
+			fileServer.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Encoding", "gzip")
+
+		http.ServeContent(w, r, name, stat.ModTime(), file)
+	})
+}
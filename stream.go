@@ -0,0 +1,99 @@
+/*
+ * This file contains the responses that are written directly instead of through Buf.
+ */
+package govel
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrResponseCommitted is returned by Stream when the headers of the response were already sent.
+var ErrResponseCommitted = errors.New("govel: the response was already sent")
+
+// streamWriter writes directly to the client.
+type streamWriter struct {
+	rw http.ResponseWriter
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	return w.rw.Write(p)
+}
+
+// Flush sends the buffered data to the client.
+func (w *streamWriter) Flush() {
+	if flusher, ok := w.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Stream sends the headers and then calls function to write the body directly to the client,
+// so the response does not need to be kept in memory.
+//
+// The sessions are saved and the headers are sent before the first write.
+// w implements http.Flusher to send the written data immediately:
+//
+//	w.(http.Flusher).Flush()
+//
+// The error returned by function is returned by Stream, but it can no longer change the response.
+// If the response was already sent, e.g. by another Stream, ErrResponseCommitted is returned and function is not called.
+func (c *Context) Stream(statusCode int, contentType string, function func(w io.Writer) error) error {
+	if c.committed {
+		return ErrResponseCommitted
+	}
+
+	if contentType != "" {
+		c.ContentType(contentType)
+	}
+
+	c.statusCode = statusCode
+
+	c.writeHeader()
+
+	w := &streamWriter{rw: c.ResponseWriter}
+
+	// anything already in the buffer goes first
+	if c.Buf.Len() > 0 {
+		w.Write(c.Buf.Bytes())
+		c.Buf.Reset()
+	}
+
+	w.Flush()
+
+	err := function(w)
+
+	w.Flush()
+
+	return err
+}
+
+// NDJSON streams newline delimited JSON ("application/x-ndjson").
+//
+// Every value passed to send is encoded in its own line and flushed immediately.
+func (c *Context) NDJSON(statusCode int, function func(send func(v interface{}) error) error) error {
+	return c.streamJSONLines(statusCode, "application/x-ndjson", function)
+}
+
+// JSONLines is the same as NDJSON but with the "application/jsonl" content type.
+func (c *Context) JSONLines(statusCode int, function func(send func(v interface{}) error) error) error {
+	return c.streamJSONLines(statusCode, "application/jsonl", function)
+}
+
+func (c *Context) streamJSONLines(statusCode int, contentType string, function func(send func(v interface{}) error) error) error {
+	return c.Stream(statusCode, contentType, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+
+		return function(func(v interface{}) error {
+			// Encode adds the new line
+			if err := encoder.Encode(v); err != nil {
+				return err
+			}
+
+			w.(http.Flusher).Flush()
+
+			return nil
+		})
+	})
+}
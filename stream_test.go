@@ -0,0 +1,38 @@
+package govel
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamCommitted(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	c.Buf.WriteString("before ")
+
+	err := c.Stream(http.StatusOK, "text/plain", func(w io.Writer) error {
+		_, err := io.WriteString(w, "streamed")
+
+		return err
+	})
+
+	if err != nil || w.Body.String() != "before streamed" {
+		t.Fatalf("Stream = %v, body %q", err, w.Body.String())
+	}
+
+	called := false
+
+	err = c.Stream(http.StatusCreated, "text/plain", func(w io.Writer) error {
+		called = true
+
+		return nil
+	})
+
+	if !errors.Is(err, ErrResponseCommitted) || called || w.Code != http.StatusOK {
+		t.Errorf("second Stream = %v, called %v, status %d", err, called, w.Code)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * This file contains the translations and the locale of the requests.
+ */
+package govel
+
+import (
+	"context"
+	"embed"
+	"net/http"
+
+	"github.com/govel-framework/govel/i18n"
+)
+
+// the validation messages in other languages than English, which are in ValidationMessages.
+//
+//go:embed lang
+var embeddedTranslations embed.FS
+
+var (
+	// Translations are the messages used by T and by the validation.
+	//
+	// The files of "i18n.dir" in the .yaml file are loaded into it, "i18n.locale" sets the fallback locale.
+	// It includes the validation messages in Spanish and German, which can be overridden with the keys "validation.<rule>".
+	Translations = newTranslations()
+
+	// LocaleQueryParam is the query parameter that changes the locale in DetectLocale, e.g. "?lang=es".
+	LocaleQueryParam = "lang"
+)
+
+type localeContextKey struct{}
+
+func newTranslations() *i18n.Bundle {
+	bundle := i18n.New("en")
+
+	err := bundle.LoadFS(embeddedTranslations, "lang")
+
+	getErr(err)
+
+	return bundle
+}
+
+// DetectLocale is a middleware that sets the locale of the request, see Context.Locale.
+//
+// The locale is the first available in Translations of: the query parameter LocaleQueryParam,
+// which is saved in the session, the locale saved in the session and the Accept-Language header.
+// Otherwise it is the fallback locale of Translations.
+func DetectLocale(c *Context) int {
+	locale := ""
+
+	if query := c.Request.URL.Query().Get(LocaleQueryParam); query != "" {
+		locale = Translations.Match(query)
+
+		if locale != "" {
+			if session, err := c.frameworkSession(); err == nil {
+				session.Set(localeSessionKey, locale)
+			}
+		}
+	}
+
+	if locale == "" {
+		if session, err := c.frameworkSession(); err == nil {
+			if saved, _ := session.Get(localeSessionKey).(string); saved != "" && Translations.Has(saved) {
+				locale = saved
+			}
+		}
+	}
+
+	if locale == "" {
+		c.addVary("Accept-Language")
+
+		locale = Translations.Match(c.GetHeader("Accept-Language"))
+	}
+
+	if locale == "" {
+		locale = Translations.Fallback()
+	}
+
+	c.setLocale(locale)
+
+	return ContinueRequest
+}
+
+// Locale returns the locale of the request set by DetectLocale or SetLocale, or the fallback locale of Translations.
+func (c *Context) Locale() string {
+	return requestLocale(c.Request)
+}
+
+// SetLocale changes the locale of the request and saves it in the session, if sessions are configured.
+func (c *Context) SetLocale(locale string) {
+	locale = i18n.Normalize(locale)
+
+	if session, err := c.frameworkSession(); err == nil {
+		session.Set(localeSessionKey, locale)
+	}
+
+	c.setLocale(locale)
+}
+
+func (c *Context) setLocale(locale string) {
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), localeContextKey{}, locale))
+
+	c.Headers["Content-Language"] = locale
+}
+
+// T translates a key to the locale of the request, see i18n.Bundle.T.
+//
+//	c.T("cart.items", govel.Map{"count": 3})
+func (c *Context) T(key string, args ...Map) string {
+	var values map[string]interface{}
+
+	if len(args) > 0 {
+		values = args[0]
+	}
+
+	return Translations.T(c.Locale(), key, values)
+}
+
+// requestLocale returns the locale stored in the context of a request, or the fallback locale.
+func requestLocale(r *http.Request) string {
+	if r != nil {
+		if locale, ok := r.Context().Value(localeContextKey{}).(string); ok {
+			return locale
+		}
+	}
+
+	return Translations.Fallback()
+}
@@ -0,0 +1,146 @@
+/*
+ * This file contains the ordered validation errors returned by ValidateAll.
+ */
+package govel
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ValidationErrors are the messages of every field that failed the validation, in the order of the fields.
+//
+// The fields are sorted by name, the messages of a field follow the order of its rules.
+// It can be returned from an action as a 422 Unprocessable Entity error.
+type ValidationErrors struct {
+	fields []string
+
+	messages map[string][]string
+
+	// the first error that kept a rule from being checked.
+	err error
+}
+
+// Add adds a message to a field.
+func (e *ValidationErrors) Add(field string, message string) {
+	if e.messages == nil {
+		e.messages = make(map[string][]string)
+	}
+
+	if _, exists := e.messages[field]; !exists {
+		e.fields = append(e.fields, field)
+	}
+
+	e.messages[field] = append(e.messages[field], message)
+}
+
+// Err returns the first error that kept a rule from being checked, e.g. the database of the unique rule
+// being down, or nil. The field of the rule has a message too, but the error should be handled as a server error.
+func (e *ValidationErrors) Err() error {
+	if e == nil {
+		return nil
+	}
+
+	return e.err
+}
+
+// Get returns the messages of a field.
+func (e *ValidationErrors) Get(field string) []string {
+	if e == nil {
+		return nil
+	}
+
+	return e.messages[field]
+}
+
+// First returns the first message of a field, or an empty string.
+func (e *ValidationErrors) First(field string) string {
+	messages := e.Get(field)
+
+	if len(messages) == 0 {
+		return ""
+	}
+
+	return messages[0]
+}
+
+// Has reports whether a field has messages.
+func (e *ValidationErrors) Has(field string) bool {
+	return len(e.Get(field)) > 0
+}
+
+// Fields returns the fields with messages, in order.
+func (e *ValidationErrors) Fields() []string {
+	if e == nil {
+		return nil
+	}
+
+	return e.fields
+}
+
+// Len returns the number of fields with messages.
+func (e *ValidationErrors) Len() int {
+	return len(e.Fields())
+}
+
+// Map returns the first message of each field, like Validate does.
+func (e *ValidationErrors) Map() map[string]string {
+	errors := make(map[string]string)
+
+	for _, field := range e.Fields() {
+		errors[field] = e.First(field)
+	}
+
+	return errors
+}
+
+// Error returns the first message of each field, one per line.
+func (e *ValidationErrors) Error() string {
+	messages := make([]string, 0, e.Len())
+
+	for _, field := range e.Fields() {
+		messages = append(messages, e.First(field))
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// HTTPError returns a 422 Unprocessable Entity error with the messages as details.
+func (e *ValidationErrors) HTTPError() *HTTPError {
+	return &HTTPError{Status: http.StatusUnprocessableEntity, Message: validationFailedMessage, Details: e}
+}
+
+// MarshalJSON encodes the errors as an object of lists of messages, keeping the order of the fields.
+func (e *ValidationErrors) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, field := range e.Fields() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(field)
+
+		if err != nil {
+			return nil, err
+		}
+
+		messages, err := json.Marshal(e.messages[field])
+
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(messages)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,150 @@
+/*
+ * This file contains the messages of the validation rules.
+ */
+package govel
+
+import (
+	"sort"
+	"strings"
+)
+
+// ValidationMessages are the default messages of the built-in rules, keyed by rule.
+//
+// The rules that check sizes have a message per type, e.g. "min.string", "min.numeric" and "min.array".
+// The messages can use the placeholders :attribute, the name of the field, and the parameters
+// of the rule: :min, :max, :values, :value, :other, :digits and :date.
+// Change them once to override the messages of every validation, OnError still overrides them per field.
+//
+// They are the English messages, the messages of other locales are the keys "validation.<rule>" of Translations.
+var ValidationMessages = map[string]string{
+	"required":         ":attribute is required",
+	"int":              ":attribute value must be an integer",
+	"min.string":       ":attribute must have at least :min characters",
+	"min.numeric":      ":attribute cannot be less than :min",
+	"min.array":        ":attribute must have at least :min items",
+	"max.string":       ":attribute cannot be longer than :max characters",
+	"max.numeric":      ":attribute cannot be greater than :max",
+	"max.array":        ":attribute cannot have more than :max items",
+	"between.string":   ":attribute must have between :min and :max characters",
+	"between.numeric":  ":attribute must be between :min and :max",
+	"between.array":    ":attribute must have between :min and :max items",
+	"email":            ":attribute is not a valid email address",
+	"url":              ":attribute is not a valid url",
+	"date":             ":attribute is not a valid date",
+	"boolean":          ":attribute is not valid",
+	"true":             ":attribute is not valid",
+	"confirm":          ":attribute and :other do not match",
+	"alpha_num":        ":attribute is not alphanumeric",
+	"numeric":          ":attribute must be a number",
+	"in":               ":attribute must be one of :values",
+	"not_in":           ":attribute cannot be :value",
+	"regex":            ":attribute format is not valid",
+	"uuid":             ":attribute is not a valid UUID",
+	"ip":               ":attribute is not a valid IP address",
+	"ipv4":             ":attribute is not a valid IPv4 address",
+	"ipv6":             ":attribute is not a valid IPv6 address",
+	"mac_address":      ":attribute is not a valid MAC address",
+	"json":             ":attribute is not a valid JSON string",
+	"timezone":         ":attribute is not a valid time zone",
+	"alpha":            ":attribute may only contain letters",
+	"alpha_dash":       ":attribute may only contain letters, numbers, dashes and underscores",
+	"digits":           ":attribute must have :digits digits",
+	"starts_with":      ":attribute must start with one of :values",
+	"ends_with":        ":attribute must end with one of :values",
+	"lowercase":        ":attribute must be lowercase",
+	"uppercase":        ":attribute must be uppercase",
+	"same":             ":attribute and :other must match",
+	"different":        ":attribute and :other must be different",
+	"before":           ":attribute must be a date before :date",
+	"after":            ":attribute must be a date after :date",
+	"required_if":      ":attribute is required when :other is :values",
+	"required_unless":  ":attribute is required unless :other is :values",
+	"required_with":    ":attribute is required when :values is present",
+	"required_without": ":attribute is required when :values is not present",
+	"prohibited_if":    ":attribute is prohibited when :other is :values",
+	"array":            ":attribute must be an array",
+	"unique":           ":attribute has already been taken",
+	"exists":           ":attribute does not exist",
+	"requiredFile":     ":attribute file is required",
+	"contentType":      "File :attribute does not have a valid content type",
+	"maxBytes":         ":attribute is too large",
+	"minFiles":         ":attribute must have at least :min files",
+	"maxFiles":         ":attribute cannot have more than :max files",
+	"maxTotalBytes":    "The files of :attribute are too large",
+	"mimes":            ":attribute must be a file of type :values",
+	"image":            ":attribute must be an image",
+	"dimensions":       ":attribute has invalid image dimensions",
+	"invalid":          ":attribute is not valid",
+	"unavailable":      ":attribute could not be checked, try again later",
+}
+
+// ValidationAttributes are the names of the fields used in the messages, e.g. "first_name": "First name".
+//
+// Nested fields can be named by their concrete path, "items.0.name", or by the key of the rules, "items.*.name".
+// The names can be translated with the keys "validation.attributes.<field>" of Translations.
+// Fields without a name use their key.
+var ValidationAttributes = map[string]string{}
+
+// validationMessage returns the message of a rule in a locale, or in English.
+func validationMessage(locale string, rule string) string {
+	if message, exists := Translations.LookupLocale(locale, "validation."+rule); exists {
+		return message
+	}
+
+	if message, exists := ValidationMessages[rule]; exists {
+		return message
+	}
+
+	return ":attribute is not valid"
+}
+
+// attributeName returns the name of the first key that has one, or the first key.
+func attributeName(locale string, keys ...string) string {
+	for _, key := range keys {
+		if name, exists := Translations.LookupLocale(locale, "validation.attributes."+key); exists {
+			return name
+		}
+
+		if name, exists := ValidationAttributes[key]; exists {
+			return name
+		}
+	}
+
+	return keys[0]
+}
+
+// formatMessage replaces the placeholders of a message, the longest first so :values is not taken for :value.
+func formatMessage(locale string, message string, attribute string, params SMap) string {
+	if !strings.Contains(message, ":") {
+		return message
+	}
+
+	replacements := map[string]string{":attribute": attribute}
+
+	for name, value := range params {
+		// "other" is the name of another field
+		if name == "other" {
+			value = attributeName(locale, value)
+		}
+
+		replacements[":"+name] = value
+	}
+
+	placeholders := make([]string, 0, len(replacements))
+
+	for placeholder := range replacements {
+		placeholders = append(placeholders, placeholder)
+	}
+
+	sort.Slice(placeholders, func(i, j int) bool {
+		return len(placeholders[i]) > len(placeholders[j])
+	})
+
+	pairs := make([]string, 0, 2*len(placeholders))
+
+	for _, placeholder := range placeholders {
+		pairs = append(pairs, placeholder, replacements[placeholder])
+	}
+
+	return strings.NewReplacer(pairs...).Replace(message)
+}
@@ -0,0 +1,127 @@
+package govel
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFormatMessage(t *testing.T) {
+	cases := []struct {
+		message string
+		params  SMap
+		want    string
+	}{
+		{":attribute is required", nil, "email is required"},
+		{"no placeholders", SMap{"min": "1"}, "no placeholders"},
+		{":attribute must have between :min and :max characters", SMap{"min": "2", "max": "10"}, "email must have between 2 and 10 characters"},
+		{":attribute must be one of :values", SMap{"values": "a, b"}, "email must be one of a, b"},
+		{":attribute cannot be :value, only :values", SMap{"value": "x", "values": "a, b"}, "email cannot be x, only a, b"},
+		{":attribute and :other must match", SMap{"other": "password"}, "email and password must match"},
+		{":attribute must be a date before :date", SMap{"date": "today"}, "email must be a date before today"},
+		{":attribute uses :unknown", nil, "email uses :unknown"},
+	}
+
+	for _, tc := range cases {
+		if got := formatMessage("en", tc.message, "email", tc.params); got != tc.want {
+			t.Errorf("formatMessage(%q) = %q, want %q", tc.message, got, tc.want)
+		}
+	}
+}
+
+func TestValidationMessages(t *testing.T) {
+	runValidatorCases(t, []validatorCase{
+		{
+			name:  "min per type",
+			form:  jsonFormOf(`{"name":"a","age":"3","tags":["x"]}`),
+			rules: Map{"name": "min:2", "age": "int|min:18", "tags": "array|min:2"},
+			errors: map[string][]string{
+				"age":  {"age cannot be less than 18"},
+				"name": {"name must have at least 2 characters"},
+				"tags": {"tags must have at least 2 items"},
+			},
+		},
+		{
+			name:  "max and between",
+			form:  jsonFormOf(`{"name":"abcd","age":"30"}`),
+			rules: Map{"name": "max:3", "age": "numeric|between:1,10"},
+			errors: map[string][]string{
+				"age":  {"age must be between 1 and 10"},
+				"name": {"name cannot be longer than 3 characters"},
+			},
+		},
+		{
+			name:  "values and value",
+			form:  formOf("color=red&size=xl&code=12"),
+			rules: Map{"color": "in:blue,green", "size": "not_in:xl", "code": "digits:3"},
+			errors: map[string][]string{
+				"code":  {"code must have 3 digits"},
+				"color": {"color must be one of blue, green"},
+				"size":  {"size cannot be xl"},
+			},
+		},
+		{
+			name:  "other",
+			form:  formOf("password=a&password_confirm=b&email=x&backup=x"),
+			rules: Map{"password": "confirm", "backup": "different:email"},
+			errors: map[string][]string{
+				"backup":   {"backup and email must be different"},
+				"password": {"password and password_confirm do not match"},
+			},
+		},
+		{
+			name:   "date",
+			form:   formOf("start=2024-01-02&end=2024-01-01"),
+			rules:  Map{"start": "before:end"},
+			errors: map[string][]string{"start": {"start must be a date before end"}},
+		},
+	})
+}
+
+func TestValidationAttributes(t *testing.T) {
+	ValidationAttributes["email"] = "E-mail address"
+	ValidationAttributes["items.*.name"] = "Item name"
+
+	t.Cleanup(func() {
+		delete(ValidationAttributes, "email")
+		delete(ValidationAttributes, "items.*.name")
+	})
+
+	runValidatorCases(t, []validatorCase{
+		{
+			name:  "by key and by pattern",
+			form:  jsonFormOf(`{"email":"x","email2":"y","items":[{"name":""}]}`),
+			rules: Map{"email": "email", "email2": "same:email", "items.*.name": "required"},
+			errors: map[string][]string{
+				"email":        {"E-mail address is not a valid email address"},
+				"email2":       {"email2 and E-mail address must match"},
+				"items.0.name": {"Item name is required"},
+			},
+		},
+	})
+}
+
+func TestValidationMessagesLocale(t *testing.T) {
+	form := newTestForm(t, "name=&age=1")
+	form.request = form.request.WithContext(context.WithValue(form.request.Context(), localeContextKey{}, "es"))
+
+	_, errors := form.ValidateAll(Map{"name": "required", "age": "int|min:18"}, nil)
+
+	if errors.First("name") != "name es obligatorio" || errors.First("age") != "age no puede ser menor que 18" {
+		t.Errorf("errors = %v", errors.Map())
+	}
+}
+
+func TestValidationMessagesOverride(t *testing.T) {
+	previous := ValidationMessages["required"]
+	ValidationMessages["required"] = "Please fill in :attribute"
+
+	t.Cleanup(func() {
+		ValidationMessages["required"] = previous
+	})
+
+	_, errors := newTestForm(t, "").Validate(Map{"name": "required"}, nil)
+
+	if errors["name"] != "Please fill in name" {
+		t.Errorf("message = %q", errors["name"])
+	}
+}
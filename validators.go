@@ -1,24 +1,49 @@
 package govel
 
 import (
+	"encoding/json"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
 	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/mail"
 	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 	"unicode"
+
+	_ "golang.org/x/image/webp"
 )
 
+// matches the parameters of before and after that are the name of a field, dates start with a digit.
+var fieldNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.*\[\]-]*$`)
+
+// matches a UUID in its canonical form, of any version.
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 /*
  * General validators.
  */
 
 func validatorRequiredField(key string, value string, form formInterface) error {
 	if value == "" {
-		return &validationError{Format: "%s is required", Key: key}
+		// lists and objects have no string value
+		if hasItems(form.getVariableForValidation()) {
+			return nil
+		}
+
+		return &validationError{Rule: "required", Key: key}
 	}
 
 	form.setVariableForValidation(value)
@@ -30,7 +55,7 @@ func validatorInt(key string, value string, form formInterface) error {
 	number, err := strconv.Atoi(value)
 
 	if err != nil {
-		return &validationError{Format: "%s value must be an integer", Key: key}
+		return &validationError{Rule: "int", Key: key}
 	}
 
 	form.setVariableForValidation(number)
@@ -46,7 +71,7 @@ func validatorString(key string, value string, form formInterface) error {
 }
 
 func validatorMin(key string, value string, form formInterface) error {
-	value_as_int, err := strconv.Atoi(value)
+	limit, err := strconv.ParseFloat(value, 64)
 
 	if err != nil {
 		panic(fmt.Sprintf("The key %s is not valid", key))
@@ -54,13 +79,23 @@ func validatorMin(key string, value string, form formInterface) error {
 
 	switch validationVarValue := form.getVariableForValidation().(type) {
 	case string:
-		if len(validationVarValue) < value_as_int {
-			return &validationError{Format: "%s must have at least " + value + " characters", Key: key}
+		if float64(len(validationVarValue)) < limit {
+			return &validationError{Rule: "min.string", Key: key, Params: SMap{"min": value}}
 		}
 
 	case int:
-		if validationVarValue < value_as_int {
-			return &validationError{Format: "%s cannot be less than " + value, Key: key}
+		if float64(validationVarValue) < limit {
+			return &validationError{Rule: "min.numeric", Key: key, Params: SMap{"min": value}}
+		}
+
+	case float64:
+		if validationVarValue < limit {
+			return &validationError{Rule: "min.numeric", Key: key, Params: SMap{"min": value}}
+		}
+
+	case []interface{}, map[string]interface{}:
+		if float64(reflect.ValueOf(validationVarValue).Len()) < limit {
+			return &validationError{Rule: "min.array", Key: key, Params: SMap{"min": value}}
 		}
 
 	}
@@ -69,7 +104,7 @@ func validatorMin(key string, value string, form formInterface) error {
 }
 
 func validatorMax(key string, value string, form formInterface) error {
-	value_as_int, err := strconv.Atoi(value)
+	limit, err := strconv.ParseFloat(value, 64)
 
 	if err != nil {
 		panic(fmt.Sprintf("The key %s is not valid", key))
@@ -77,13 +112,23 @@ func validatorMax(key string, value string, form formInterface) error {
 
 	switch validationVarValue := form.getVariableForValidation().(type) {
 	case string:
-		if len(validationVarValue) > value_as_int {
-			return &validationError{Format: "%s cannot be longer than " + value + " characters", Key: key}
+		if float64(len(validationVarValue)) > limit {
+			return &validationError{Rule: "max.string", Key: key, Params: SMap{"max": value}}
 		}
 
 	case int:
-		if validationVarValue > value_as_int {
-			return &validationError{Format: "%s cannot be greater than " + value, Key: key}
+		if float64(validationVarValue) > limit {
+			return &validationError{Rule: "max.numeric", Key: key, Params: SMap{"max": value}}
+		}
+
+	case float64:
+		if validationVarValue > limit {
+			return &validationError{Rule: "max.numeric", Key: key, Params: SMap{"max": value}}
+		}
+
+	case []interface{}, map[string]interface{}:
+		if float64(reflect.ValueOf(validationVarValue).Len()) > limit {
+			return &validationError{Rule: "max.array", Key: key, Params: SMap{"max": value}}
 		}
 
 	}
@@ -95,14 +140,23 @@ func validatorEmail(key string, value string, form formInterface) error {
 	_, err := mail.ParseAddress(value)
 
 	if err != nil {
-		return &validationError{Format: "%s is not a valid email address", Key: key}
+		return &validationError{Rule: "email", Key: key}
 	}
 
 	return nil
 }
 
+// validatorBail does nothing, formValidator stops at the first error of the fields that have it.
+func validatorBail(key string, value string, form formInterface) error {
+	return nil
+}
+
 func validatorOptionalField(key string, value string, form formInterface) error {
 	if value == "" {
+		if hasItems(form.getVariableForValidation()) {
+			return nil
+		}
+
 		form.setSkipValidation(true)
 	}
 
@@ -111,11 +165,30 @@ func validatorOptionalField(key string, value string, form formInterface) error
 	return nil
 }
 
+// validatorArray validates that the field is a list or an object, min and max then count its elements.
+func validatorArray(key string, value string, form formInterface) error {
+	current := form.getVariableForValidation()
+
+	// a key repeated in a form is a list, but it starts with its first value
+	if _, isString := current.(string); isString {
+		current, _ = lookupField(form.fields(), strings.Split(key, "."))
+	}
+
+	switch current.(type) {
+	case []interface{}, map[string]interface{}:
+		form.setVariableForValidation(current)
+
+		return nil
+	}
+
+	return &validationError{Rule: "array", Key: key}
+}
+
 func validatorUrl(key string, value string, form formInterface) error {
 	url, err := url.ParseRequestURI(value)
 
 	if err != nil || url.Scheme == "" || url.Host == "" || (strings.Index(url.Host, ".") <= 0 || strings.LastIndex(url.Host, ".") == len(url.Host)-1) {
-		return &validationError{Format: "%s is not a valid url", Key: key}
+		return &validationError{Rule: "url", Key: key}
 	}
 
 	form.setVariableForValidation(url)
@@ -124,46 +197,460 @@ func validatorUrl(key string, value string, form formInterface) error {
 }
 
 func validatorCofirm(key string, value string, f formInterface) error {
-	var confirmValue string
+	confirmKey := fmt.Sprintf("%s_confirm", key)
+
+	if value != f.Get(confirmKey) {
+		return &validationError{Rule: "confirm", Key: key, Params: SMap{"other": confirmKey}}
+	}
+
+	return nil
+}
 
-	form, isForm := f.(*Form)
+func validatorAlphaNum(key string, value string, f formInterface) error {
+	var isAlphaNum bool = true
 
-	if isForm {
-		confirmValue = form.Get(fmt.Sprintf("%s_confirm", key))
+	for _, char := range value {
+		if !unicode.IsLetter(char) && !unicode.IsDigit(char) && char != ' ' {
+			isAlphaNum = false
+			break
+		}
 	}
 
-	multiPartForm, isMultipartForm := f.(*MultipartFormData)
+	if !isAlphaNum {
+		return &validationError{Rule: "alpha_num", Key: key}
+	}
 
-	if isMultipartForm {
-		confirmValue = multiPartForm.Get(fmt.Sprintf("%s_confirm", key))
+	return nil
+}
+
+func validatorNumeric(key string, value string, form formInterface) error {
+	number, err := strconv.ParseFloat(value, 64)
+
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return &validationError{Rule: "numeric", Key: key}
+	}
+
+	form.setVariableForValidation(number)
+
+	return nil
+}
+
+func validatorBetween(key string, value string, form formInterface) error {
+	limits := strings.Split(value, ",")
+
+	if len(limits) != 2 {
+		panic(fmt.Sprintf("\"%s\" is not valid for between", value))
 	}
 
-	if value != confirmValue {
-		e := fmt.Sprintf("%s and %s do not match", value, confirmValue)
+	min, errMin := strconv.ParseFloat(strings.TrimSpace(limits[0]), 64)
+	max, errMax := strconv.ParseFloat(strings.TrimSpace(limits[1]), 64)
+
+	if errMin != nil || errMax != nil {
+		panic(fmt.Sprintf("\"%s\" is not valid for between", value))
+	}
+
+	bounds := SMap{"min": strings.TrimSpace(limits[0]), "max": strings.TrimSpace(limits[1])}
+
+	switch validationVarValue := form.getVariableForValidation().(type) {
+	case string:
+		if length := float64(len(validationVarValue)); length < min || length > max {
+			return &validationError{Rule: "between.string", Key: key, Params: bounds}
+		}
+
+	case int:
+		if number := float64(validationVarValue); number < min || number > max {
+			return &validationError{Rule: "between.numeric", Key: key, Params: bounds}
+		}
+
+	case float64:
+		if validationVarValue < min || validationVarValue > max {
+			return &validationError{Rule: "between.numeric", Key: key, Params: bounds}
+		}
+
+	case []interface{}, map[string]interface{}:
+		if count := float64(reflect.ValueOf(validationVarValue).Len()); count < min || count > max {
+			return &validationError{Rule: "between.array", Key: key, Params: bounds}
+		}
 
-		return &validationError{Format: e}
 	}
 
 	return nil
 }
 
-func validatorAlphaNum(key string, value string, f formInterface) error {
-	var isAlphaNum bool = true
+func validatorIn(key string, value string, form formInterface) error {
+	current := validationString(form)
+
+	for _, option := range strings.Split(value, ",") {
+		if current == strings.TrimSpace(option) {
+			return nil
+		}
+	}
+
+	return &validationError{Rule: "in", Key: key, Params: SMap{"values": strings.Join(splitParams(value), ", ")}}
+}
+
+func validatorNotIn(key string, value string, form formInterface) error {
+	current := validationString(form)
+
+	for _, option := range strings.Split(value, ",") {
+		if current == strings.TrimSpace(option) {
+			return &validationError{Rule: "not_in", Key: key, Params: SMap{"value": current}}
+		}
+	}
+
+	return nil
+}
+
+// validatorRegex validates the value with a regular expression.
+//
+// The expression can contain ":", but not "|", which separates the rules. Use a []string of rules for those.
+func validatorRegex(key string, value string, form formInterface) error {
+	regex, err := regexp.Compile(value)
+
+	if err != nil {
+		panic(fmt.Sprintf("\"%s\" is not a valid regular expression", value))
+	}
+
+	if !regex.MatchString(validationString(form)) {
+		return &validationError{Rule: "regex", Key: key}
+	}
+
+	return nil
+}
+
+func validatorUUID(key string, value string, form formInterface) error {
+	if !uuidRegex.MatchString(value) {
+		return &validationError{Rule: "uuid", Key: key}
+	}
+
+	return nil
+}
+
+func validatorIP(key string, value string, form formInterface) error {
+	ip := net.ParseIP(value)
+
+	if ip == nil {
+		return &validationError{Rule: "ip", Key: key}
+	}
+
+	form.setVariableForValidation(ip)
+
+	return nil
+}
+
+func validatorIPv4(key string, value string, form formInterface) error {
+	ip := net.ParseIP(value)
+
+	if ip == nil || ip.To4() == nil || strings.Contains(value, ":") {
+		return &validationError{Rule: "ipv4", Key: key}
+	}
+
+	form.setVariableForValidation(ip)
+
+	return nil
+}
+
+func validatorIPv6(key string, value string, form formInterface) error {
+	ip := net.ParseIP(value)
+
+	if ip == nil || !strings.Contains(value, ":") {
+		return &validationError{Rule: "ipv6", Key: key}
+	}
+
+	form.setVariableForValidation(ip)
+
+	return nil
+}
+
+func validatorMacAddress(key string, value string, form formInterface) error {
+	mac, err := net.ParseMAC(value)
+
+	if err != nil {
+		return &validationError{Rule: "mac_address", Key: key}
+	}
+
+	form.setVariableForValidation(mac)
+
+	return nil
+}
+
+func validatorJSON(key string, value string, form formInterface) error {
+	if !json.Valid([]byte(value)) {
+		return &validationError{Rule: "json", Key: key}
+	}
+
+	return nil
+}
 
+func validatorTimezone(key string, value string, form formInterface) error {
+	// LoadLocation accepts "" and "Local", which are not time zone names
+	if value == "" || value == "Local" {
+		return &validationError{Rule: "timezone", Key: key}
+	}
+
+	location, err := time.LoadLocation(value)
+
+	if err != nil {
+		return &validationError{Rule: "timezone", Key: key}
+	}
+
+	form.setVariableForValidation(location)
+
+	return nil
+}
+
+// String validators
+
+func validatorAlpha(key string, value string, form formInterface) error {
 	for _, char := range value {
-		if !unicode.IsLetter(char) && !unicode.IsDigit(char) && char != ' ' {
-			isAlphaNum = false
+		if !unicode.IsLetter(char) {
+			return &validationError{Rule: "alpha", Key: key}
+		}
+	}
+
+	return nil
+}
+
+func validatorAlphaDash(key string, value string, form formInterface) error {
+	for _, char := range value {
+		if !unicode.IsLetter(char) && !unicode.IsDigit(char) && char != '-' && char != '_' {
+			return &validationError{Rule: "alpha_dash", Key: key}
+		}
+	}
+
+	return nil
+}
+
+func validatorDigits(key string, value string, form formInterface) error {
+	length, err := strconv.Atoi(value)
+
+	if err != nil {
+		panic(fmt.Sprintf("\"%s\" is not valid for digits", value))
+	}
+
+	current := validationString(form)
+
+	if len(current) != length || strings.IndexFunc(current, func(char rune) bool { return char < '0' || char > '9' }) >= 0 {
+		return &validationError{Rule: "digits", Key: key, Params: SMap{"digits": value}}
+	}
+
+	return nil
+}
+
+func validatorStartsWith(key string, value string, form formInterface) error {
+	current := validationString(form)
+
+	for _, prefix := range strings.Split(value, ",") {
+		if strings.HasPrefix(current, strings.TrimSpace(prefix)) {
+			return nil
+		}
+	}
+
+	return &validationError{Rule: "starts_with", Key: key, Params: SMap{"values": strings.Join(splitParams(value), ", ")}}
+}
+
+func validatorEndsWith(key string, value string, form formInterface) error {
+	current := validationString(form)
+
+	for _, suffix := range strings.Split(value, ",") {
+		if strings.HasSuffix(current, strings.TrimSpace(suffix)) {
+			return nil
+		}
+	}
+
+	return &validationError{Rule: "ends_with", Key: key, Params: SMap{"values": strings.Join(splitParams(value), ", ")}}
+}
+
+func validatorLowercase(key string, value string, form formInterface) error {
+	if value != strings.ToLower(value) {
+		return &validationError{Rule: "lowercase", Key: key}
+	}
+
+	return nil
+}
+
+func validatorUppercase(key string, value string, form formInterface) error {
+	if value != strings.ToUpper(value) {
+		return &validationError{Rule: "uppercase", Key: key}
+	}
+
+	return nil
+}
+
+// Comparison validators
+
+func validatorSame(key string, value string, form formInterface) error {
+	if other, _ := otherFieldValue(form, key, value); validationString(form) != other {
+		return &validationError{Rule: "same", Key: key, Params: SMap{"other": value}}
+	}
+
+	return nil
+}
+
+func validatorDifferent(key string, value string, form formInterface) error {
+	if other, _ := otherFieldValue(form, key, value); validationString(form) == other {
+		return &validationError{Rule: "different", Key: key, Params: SMap{"other": value}}
+	}
+
+	return nil
+}
+
+// validationString returns the value being validated as a string.
+func validationString(form formInterface) string {
+	switch validationVarValue := form.getVariableForValidation().(type) {
+	case string:
+		return validationVarValue
+
+	case nil:
+		return ""
+
+	default:
+		return fmt.Sprint(validationVarValue)
+	}
+}
+
+// Conditional validators
+
+func validatorRequiredIf(key string, value string, form formInterface) error {
+	field, values := conditionParams("required_if", value)
+
+	return requiredWhen(key, form, fieldIn(form, key, field, values), &validationError{Rule: "required_if", Key: key, Params: SMap{"other": field, "values": strings.Join(values, ", ")}})
+}
+
+func validatorRequiredUnless(key string, value string, form formInterface) error {
+	field, values := conditionParams("required_unless", value)
+
+	return requiredWhen(key, form, !fieldIn(form, key, field, values), &validationError{Rule: "required_unless", Key: key, Params: SMap{"other": field, "values": strings.Join(values, ", ")}})
+}
+
+func validatorRequiredWith(key string, value string, form formInterface) error {
+	fields := splitParams(value)
+	required := false
+
+	for _, field := range fields {
+		if _, filled := otherFieldValue(form, key, field); filled {
+			required = true
 			break
 		}
 	}
 
-	if !isAlphaNum {
-		return &validationError{Format: "%s is not alphanumeric", Key: key}
+	return requiredWhen(key, form, required, &validationError{Rule: "required_with", Key: key, Params: SMap{"values": strings.Join(fields, ", ")}})
+}
+
+func validatorRequiredWithout(key string, value string, form formInterface) error {
+	fields := splitParams(value)
+	required := false
+
+	for _, field := range fields {
+		if _, filled := otherFieldValue(form, key, field); !filled {
+			required = true
+			break
+		}
+	}
+
+	return requiredWhen(key, form, required, &validationError{Rule: "required_without", Key: key, Params: SMap{"values": strings.Join(fields, ", ")}})
+}
+
+func validatorProhibitedIf(key string, value string, form formInterface) error {
+	field, values := conditionParams("prohibited_if", value)
+
+	if fieldIn(form, key, field, values) && validationString(form) != "" {
+		return &validationError{Rule: "prohibited_if", Key: key, Params: SMap{"other": field, "values": strings.Join(values, ", ")}}
 	}
 
 	return nil
 }
 
+// validatorExcludeIf leaves the field out of the validation and of "data" if the condition is met.
+func validatorExcludeIf(key string, value string, form formInterface) error {
+	field, values := conditionParams("exclude_if", value)
+
+	if fieldIn(form, key, field, values) {
+		form.setSkipValidation(true)
+	}
+
+	return nil
+}
+
+// validatorSometimes only validates the field if it is present in the form, even if it is empty.
+func validatorSometimes(key string, value string, form formInterface) error {
+	if !fieldExists(form, key) {
+		form.setSkipValidation(true)
+	}
+
+	return nil
+}
+
+// requiredWhen works like "required" if required is true and like "optional" otherwise.
+func requiredWhen(key string, form formInterface, required bool, err *validationError) error {
+	current := validationString(form)
+
+	if current != "" {
+		return nil
+	}
+
+	if required {
+		return err
+	}
+
+	form.setSkipValidation(true)
+
+	return nil
+}
+
+// conditionParams splits the "field,value,..." parameter of a conditional rule.
+func conditionParams(rule string, value string) (field string, values []string) {
+	params := splitParams(value)
+
+	if len(params) < 2 {
+		panic(fmt.Sprintf("\"%s\" is not valid for %s, the format is %s:field,value", value, rule, rule))
+	}
+
+	return params[0], params[1:]
+}
+
+// fieldIn reports whether the value of field is one of values.
+func fieldIn(form formInterface, key string, field string, values []string) bool {
+	other, _ := otherFieldValue(form, key, field)
+
+	for _, value := range values {
+		if other == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// otherFieldValue returns the trimmed value of the field a rule of key refers to, and whether it is filled.
+//
+// The field can be a nested path, and a "*" is the index of key at the same level, e.g. "items.*.price"
+// for the key "items.2.quantity" is "items.2.price". A list, like the files of a field, is filled if it has items.
+func otherFieldValue(form formInterface, key string, field string) (string, bool) {
+	keyParts := strings.Split(key, ".")
+	fieldParts := strings.Split(field, ".")
+
+	for i, part := range fieldParts {
+		if part == "*" && i < len(keyParts) {
+			fieldParts[i] = keyParts[i]
+		}
+	}
+
+	value, current := fieldValue(form, strings.Join(fieldParts, "."))
+
+	return value, value != "" || hasItems(current)
+}
+
+func splitParams(value string) []string {
+	params := strings.Split(value, ",")
+
+	for i := range params {
+		params[i] = strings.TrimSpace(params[i])
+	}
+
+	return params
+}
+
 // Date validators
 
 func validatorDate(key string, value string, form formInterface) error {
@@ -180,10 +667,10 @@ func validatorDate(key string, value string, form formInterface) error {
 		value = strings.ReplaceAll(value, key, keyValue)
 	}
 
-	date, err := time.Parse(value, form.getVariableForValidation().(string))
+	date, err := time.Parse(value, validationString(form))
 
 	if err != nil {
-		return &validationError{Format: "%s is not a valid date", Key: key}
+		return &validationError{Rule: "date", Key: key}
 	}
 
 	form.setVariableForValidation(date)
@@ -191,13 +678,102 @@ func validatorDate(key string, value string, form formInterface) error {
 	return nil
 }
 
+func validatorBefore(key string, value string, form formInterface) error {
+	date, limit, ok := comparableDates(key, value, form)
+
+	if !ok || !date.Before(limit) {
+		return &validationError{Rule: "before", Key: key, Params: SMap{"date": value}}
+	}
+
+	return nil
+}
+
+func validatorAfter(key string, value string, form formInterface) error {
+	date, limit, ok := comparableDates(key, value, form)
+
+	if !ok || !date.After(limit) {
+		return &validationError{Rule: "after", Key: key, Params: SMap{"date": value}}
+	}
+
+	return nil
+}
+
+// the layouts tried when a date is compared without the "date" rule.
+var dateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// comparableDates returns the date being validated and the date of the parameter of before and after.
+//
+// The parameter can be a date, "now", "today", "tomorrow", "yesterday" or the name of another field.
+// A date converted by the "date" rule is used as is, otherwise it is parsed with dateLayouts.
+func comparableDates(key string, value string, form formInterface) (date time.Time, limit time.Time, ok bool) {
+	switch validationVarValue := form.getVariableForValidation().(type) {
+	case time.Time:
+		date = validationVarValue
+
+	default:
+		date, ok = parseDate(validationString(form))
+
+		if !ok {
+			return date, limit, false
+		}
+
+		form.setVariableForValidation(date)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch value {
+	case "now":
+		return date, now, true
+
+	case "today":
+		return date, today, true
+
+	case "tomorrow":
+		return date, today.AddDate(0, 0, 1), true
+
+	case "yesterday":
+		return date, today.AddDate(0, 0, -1), true
+	}
+
+	// the name of another field, an empty or invalid field is not comparable
+	if fieldNameRegex.MatchString(value) {
+		other, _ := otherFieldValue(form, key, value)
+
+		limit, ok = parseDate(other)
+
+		return date, limit, ok
+	}
+
+	limit, ok = parseDate(value)
+
+	if !ok {
+		panic(fmt.Sprintf("\"%s\" is not a valid date for the %s rules", value, key))
+	}
+
+	return date, limit, true
+}
+
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		date, err := time.ParseInLocation(layout, value, time.Local)
+
+		if err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
 // Boolean validators
 
 func validatorBoolean(key string, value string, form formInterface) error {
 	value_to_boolean, err := strconv.ParseBool(value)
 
 	if err != nil {
-		return &validationError{Format: "%s is not valid", Key: key}
+		return &validationError{Rule: "boolean", Key: key}
 	}
 
 	form.setVariableForValidation(value_to_boolean)
@@ -206,8 +782,8 @@ func validatorBoolean(key string, value string, form formInterface) error {
 }
 
 func validatorTrue(key string, value string, form formInterface) error {
-	if form.getVariableForValidation().(bool) == false {
-		return &validationError{Format: "%s is not valid", Key: key}
+	if isTrue, _ := form.getVariableForValidation().(bool); !isTrue {
+		return &validationError{Rule: "true", Key: key}
 	}
 
 	return nil
@@ -237,7 +813,7 @@ func validatorRequiredFile(key string, value string, form formInterface) error {
 	file, err := f.GetFile(key)
 
 	if err != nil {
-		return &validationError{Format: "%s file is required", Key: key}
+		return &validationError{Rule: "requiredFile", Key: key}
 	}
 
 	form.setVariableForValidation(file)
@@ -246,11 +822,15 @@ func validatorRequiredFile(key string, value string, form formInterface) error {
 }
 
 func validatorContentType(key string, value string, form formInterface) error {
+	formFile, closeFile, isFile := ruleFile(key, form)
+	defer closeFile()
 
-	// validate the file
-	content, _ := io.ReadAll(form.getVariableForValidation().(FormFile).File)
+	// validate the file, only the first 512 bytes are used and the file is not moved
+	contentType := ""
 
-	contentType := http.DetectContentType(content)
+	if isFile {
+		contentType, _ = sniffContentType(formFile.File)
+	}
 
 	// validate the contentType
 	contentTypes := strings.Split(value, ",")
@@ -258,31 +838,353 @@ func validatorContentType(key string, value string, form formInterface) error {
 	isValidContentType := false
 
 	for _, ct := range contentTypes {
-		if contentType == ct {
+		if ct = strings.TrimSpace(ct); ct != "" && (contentType == ct || mediaTypeOf(contentType) == ct) {
 			isValidContentType = true
 			break
 		}
 	}
 
 	if !isValidContentType {
-		return &validationError{Format: "File %s does not have a valid content type", Key: key}
+		return &validationError{Rule: "contentType", Key: key, Params: SMap{"values": strings.Join(splitParams(value), ", ")}}
 	}
 
 	return nil
 }
 
 func validatorMaxBytes(key string, value string, form formInterface) error {
-	formFile := form.getVariableForValidation().(FormFile)
-
 	value_as_int, err := strconv.Atoi(value)
 
 	if err != nil {
 		panic(fmt.Sprintf("\"%s\" is not valid for maxBytes", value))
 	}
 
-	if formFile.FileHeader.Size > int64(value_as_int) {
-		return &validationError{Format: "%s is too large", Key: key}
+	formFile, closeFile, isFile := ruleFile(key, form)
+	defer closeFile()
+
+	if !isFile || formFile.FileHeader == nil || formFile.FileHeader.Size > int64(value_as_int) {
+		return &validationError{Rule: "maxBytes", Key: key, Params: SMap{"max": value}}
 	}
 
 	return nil
 }
+
+func validatorMinFiles(key string, value string, form formInterface) error {
+	f, _ := form.(*MultipartFormData)
+
+	if len(f.fileHeaders(key)) < fileRuleInt("minFiles", value) {
+		return &validationError{Rule: "minFiles", Key: key, Params: SMap{"min": value}}
+	}
+
+	return nil
+}
+
+func validatorMaxFiles(key string, value string, form formInterface) error {
+	f, _ := form.(*MultipartFormData)
+
+	if len(f.fileHeaders(key)) > fileRuleInt("maxFiles", value) {
+		return &validationError{Rule: "maxFiles", Key: key, Params: SMap{"max": value}}
+	}
+
+	return nil
+}
+
+// validatorMaxTotalBytes validates the size of all the files of the field together.
+func validatorMaxTotalBytes(key string, value string, form formInterface) error {
+	f, _ := form.(*MultipartFormData)
+
+	var total int64
+
+	for _, header := range f.fileHeaders(key) {
+		total += header.Size
+	}
+
+	if total > int64(fileRuleInt("maxTotalBytes", value)) {
+		return &validationError{Rule: "maxTotalBytes", Key: key, Params: SMap{"max": value}}
+	}
+
+	return nil
+}
+
+// validatorMimes validates the extension of the file and that its content does not belong to another type.
+func validatorMimes(key string, value string, form formInterface) error {
+	formFile, closeFile, isFile := ruleFile(key, form)
+	defer closeFile()
+
+	if !isFile {
+		return &validationError{Rule: "mimes", Key: key, Params: SMap{"values": strings.Join(splitParams(value), ", ")}}
+	}
+
+	extension := strings.ToLower(filepath.Ext(formFile.FileHeader.Filename))
+	extensionType := mediaTypeOf(mime.TypeByExtension(extension))
+
+	allowed := false
+
+	for _, accepted := range splitParams(value) {
+		accepted = "." + strings.ToLower(strings.TrimPrefix(accepted, "."))
+
+		// "jpg" also accepts ".jpeg"
+		if accepted == extension || (extensionType != "" && mediaTypeOf(mime.TypeByExtension(accepted)) == extensionType) {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed || !contentMatchesExtension(formFile.File, extension) {
+		return &validationError{Rule: "mimes", Key: key, Params: SMap{"values": strings.Join(splitParams(value), ", ")}}
+	}
+
+	return nil
+}
+
+// the extensions whose content is recognised by http.DetectContentType, with the type it detects.
+var sniffableExtensions = map[string]string{
+	".png":   "image/png",
+	".jpg":   "image/jpeg",
+	".jpeg":  "image/jpeg",
+	".gif":   "image/gif",
+	".webp":  "image/webp",
+	".bmp":   "image/bmp",
+	".ico":   "image/x-icon",
+	".pdf":   "application/pdf",
+	".zip":   "application/zip",
+	".gz":    "application/x-gzip",
+	".rar":   "application/x-rar-compressed",
+	".mp4":   "video/mp4",
+	".webm":  "video/webm",
+	".ogg":   "application/ogg",
+	".wav":   "audio/wave",
+	".wasm":  "application/wasm",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+}
+
+// contentMatchesExtension sniffs the content of the file, it does not match if it is of a known type of another extension.
+func contentMatchesExtension(file multipart.File, extension string) bool {
+	contentType, err := sniffContentType(file)
+
+	if err != nil {
+		return false
+	}
+
+	sniffed := mediaTypeOf(contentType)
+
+	// the content of these extensions is always recognised, so a generic type does not match
+	if expected, isSniffable := sniffableExtensions[extension]; isSniffable {
+		return sniffed == expected
+	}
+
+	switch sniffed {
+	// types too generic to tell the extension
+	case "application/octet-stream", "text/plain", "application/zip":
+		return true
+	}
+
+	if mediaTypeOf(mime.TypeByExtension(extension)) == sniffed {
+		return true
+	}
+
+	extensions, _ := mime.ExtensionsByType(sniffed)
+
+	for _, candidate := range extensions {
+		if candidate == extension {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sniffContentType detects the content type of a file from its first 512 bytes.
+//
+// The file is read with ReadAt, so its offset does not change.
+func sniffContentType(file multipart.File) (string, error) {
+	buf := make([]byte, 512)
+
+	n, err := file.ReadAt(buf, 0)
+
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// validatorImage validates that the file is an image in a format that can be decoded: PNG, JPEG, GIF or WebP.
+func validatorImage(key string, value string, form formInterface) error {
+	formFile, closeFile, isFile := ruleFile(key, form)
+	defer closeFile()
+
+	if !isFile {
+		return &validationError{Rule: "image", Key: key}
+	}
+
+	if _, _, err := imageConfig(formFile); err != nil {
+		return &validationError{Rule: "image", Key: key}
+	}
+
+	return nil
+}
+
+// validatorDimensions validates the size of an image, the parameter is a list of constraints:
+// width, height, min_width, max_width, min_height, max_height and ratio, e.g. "min_width=100,ratio=16/9".
+func validatorDimensions(key string, value string, form formInterface) error {
+	formFile, closeFile, isFile := ruleFile(key, form)
+	defer closeFile()
+
+	if !isFile {
+		return &validationError{Rule: "dimensions", Key: key}
+	}
+
+	config, _, err := imageConfig(formFile)
+
+	if err != nil {
+		return &validationError{Rule: "dimensions", Key: key}
+	}
+
+	for _, constraint := range splitParams(value) {
+		name, limit, found := strings.Cut(constraint, "=")
+
+		if !found {
+			panic(fmt.Sprintf("\"%s\" is not valid for dimensions", value))
+		}
+
+		if name == "ratio" {
+			if !imageRatioMatches(config.Width, config.Height, limit) {
+				return &validationError{Rule: "dimensions", Key: key}
+			}
+
+			continue
+		}
+
+		size, err := strconv.Atoi(limit)
+
+		if err != nil {
+			panic(fmt.Sprintf("\"%s\" is not valid for dimensions", value))
+		}
+
+		valid := true
+
+		switch name {
+		case "width":
+			valid = config.Width == size
+		case "height":
+			valid = config.Height == size
+		case "min_width":
+			valid = config.Width >= size
+		case "max_width":
+			valid = config.Width <= size
+		case "min_height":
+			valid = config.Height >= size
+		case "max_height":
+			valid = config.Height <= size
+		default:
+			panic(fmt.Sprintf("\"%s\" is not a constraint of dimensions", name))
+		}
+
+		if !valid {
+			return &validationError{Rule: "dimensions", Key: key}
+		}
+	}
+
+	return nil
+}
+
+// imageRatioMatches compares the ratio of an image with "16/9" or "1.5", with the precision of one pixel.
+func imageRatioMatches(width int, height int, ratio string) bool {
+	numerator, denominator, isFraction := strings.Cut(ratio, "/")
+
+	if !isFraction {
+		denominator = "1"
+	}
+
+	a, errA := strconv.ParseFloat(numerator, 64)
+	b, errB := strconv.ParseFloat(denominator, 64)
+
+	if errA != nil || errB != nil || b == 0 {
+		panic(fmt.Sprintf("\"%s\" is not a valid ratio", ratio))
+	}
+
+	if height == 0 {
+		return false
+	}
+
+	longest := width
+
+	if height > longest {
+		longest = height
+	}
+
+	return math.Abs(float64(width)/float64(height)-a/b) <= 1/float64(longest+1)
+}
+
+// imageConfig decodes the header of an image, the offset of the file does not change.
+func imageConfig(formFile FormFile) (image.Config, string, error) {
+	size := int64(math.MaxInt64)
+
+	if formFile.FileHeader != nil {
+		size = formFile.FileHeader.Size
+	}
+
+	return image.DecodeConfig(io.NewSectionReader(formFile.File, 0, size))
+}
+
+// ruleFile returns the file being validated and a function that closes it once the rule is done.
+//
+// The file of "requiredFile" and "optionalFile" stays open, it is returned in "data". Otherwise the file
+// is opened from its header, which is kept for the next rules and for "data", and closed by the function.
+func ruleFile(key string, form formInterface) (FormFile, func(), bool) {
+	current, isFile := form.getVariableForValidation().(FormFile)
+
+	if isFile && current.File != nil {
+		return current, func() {}, true
+	}
+
+	header := current.FileHeader
+
+	if header == nil {
+		f, isMultipart := form.(*MultipartFormData)
+
+		if !isMultipart {
+			return FormFile{}, func() {}, false
+		}
+
+		headers := f.fileHeaders(key)
+
+		if len(headers) == 0 {
+			return FormFile{}, func() {}, false
+		}
+
+		header = headers[0]
+	}
+
+	file, err := header.Open()
+
+	if err != nil {
+		return FormFile{}, func() {}, false
+	}
+
+	form.setVariableForValidation(FormFile{FileHeader: header})
+
+	return FormFile{File: file, FileHeader: header}, func() { file.Close() }, true
+}
+
+// mediaTypeOf returns a content type without its parameters, e.g. "text/plain" for "text/plain; charset=utf-8".
+func mediaTypeOf(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return ""
+	}
+
+	return mediaType
+}
+
+func fileRuleInt(rule string, value string) int {
+	number, err := strconv.Atoi(value)
+
+	if err != nil {
+		panic(fmt.Sprintf("\"%s\" is not valid for %s", value, rule))
+	}
+
+	return number
+}
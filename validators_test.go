@@ -0,0 +1,156 @@
+package govel
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestMultipartForm returns a MultipartFormData with the values and the files, keyed by field name with their content.
+func newTestMultipartForm(t *testing.T, values map[string]string, files map[string][]testFile) *MultipartFormData {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+
+	for key, value := range values {
+		w.WriteField(key, value)
+	}
+
+	for key, list := range files {
+		for _, file := range list {
+			part, err := w.CreateFormFile(key, file.name)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			part.Write(file.content)
+		}
+	}
+
+	w.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		r.MultipartForm.RemoveAll()
+	})
+
+	return &MultipartFormData{request: r}
+}
+
+type testFile struct {
+	name    string
+	content []byte
+}
+
+func TestOtherFieldRules(t *testing.T) {
+	runValidatorCases(t, []validatorCase{
+		{
+			name:  "nested same and different",
+			form:  jsonFormOf(`{"address":{"city":"Berlin","billing_city":"Bonn"},"city":"Berlin"}`),
+			rules: Map{"city": "same:address.city", "address.billing_city": "different:address.city"},
+		},
+		{
+			name:   "nested same fails",
+			form:   jsonFormOf(`{"address":{"city":"Berlin"},"city":"Bonn"}`),
+			rules:  Map{"city": "same:address.city"},
+			errors: map[string][]string{"city": {"city and address.city must match"}},
+		},
+		{
+			name:  "wildcard siblings",
+			form:  jsonFormOf(`{"items":[{"type":"paid","price":"3"},{"type":"free"},{"type":"paid"}]}`),
+			rules: Map{"items.*.price": "required_if:items.*.type,paid"},
+			errors: map[string][]string{
+				"items.2.price": {"items.2.price is required when items.*.type is paid"},
+			},
+		},
+		{
+			name:  "wildcard required_with",
+			form:  formOf("rows[0][from]=1&rows[0][to]=2&rows[1][from]=3"),
+			rules: Map{"rows.*.to": "required_with:rows.*.from", "rows.*.from": "required_without:rows.*.to"},
+			errors: map[string][]string{
+				"rows.1.to": {"rows.1.to is required when rows.*.from is present"},
+			},
+		},
+		{
+			name:  "wildcard before",
+			form:  jsonFormOf(`{"periods":[{"start":"2024-01-01","end":"2024-02-01"},{"start":"2024-03-01","end":"2024-02-01"}]}`),
+			rules: Map{"periods.*.start": "before:periods.*.end"},
+			errors: map[string][]string{
+				"periods.1.start": {"periods.1.start must be a date before periods.*.end"},
+			},
+		},
+		{
+			name:   "before an empty field",
+			form:   formOf("start=2024-01-01&end="),
+			rules:  Map{"start": "required|before:end"},
+			errors: map[string][]string{"start": {"start must be a date before end"}},
+		},
+		{
+			name:  "nested required_without",
+			form:  jsonFormOf(`{"contact":{"phone":""}}`),
+			rules: Map{"contact.email": "required_without:contact.phone"},
+			errors: map[string][]string{
+				"contact.email": {"contact.email is required when contact.phone is not present"},
+			},
+		},
+	})
+}
+
+func TestOtherFieldRulesWithFiles(t *testing.T) {
+	form := newTestMultipartForm(t, nil, map[string][]testFile{"avatar": {{"a.txt", []byte("hi")}}})
+
+	_, errors := form.ValidateAll(Map{"caption": "required_with:avatar", "url": "required_without:avatar"}, nil)
+
+	if !errors.Has("caption") || errors.Has("url") {
+		t.Errorf("errors = %v", errors.Map())
+	}
+}
+
+func TestFileRulesReportEveryError(t *testing.T) {
+	form := newTestMultipartForm(t, nil, map[string][]testFile{
+		"avatar":   {{"x.png", []byte("not an image")}},
+		"photos[]": {{"a.png", []byte("text")}, {"b.png", []byte("more text")}},
+	})
+
+	_, errors := form.ValidateAll(Map{"avatar": "mimes:png|maxBytes:4|image", "photos.*": "maxBytes:4|mimes:png"}, nil)
+
+	want := map[string]int{"avatar": 3, "photos.0": 1, "photos.1": 2}
+
+	for field, count := range want {
+		if got := len(errors.Get(field)); got != count {
+			t.Errorf("%s has %d errors, want %d: %v", field, got, count, errors.Get(field))
+		}
+	}
+
+	_, errors = form.ValidateAll(Map{"avatar": "bail|mimes:png|maxBytes:4"}, nil)
+
+	if len(errors.Get("avatar")) != 1 {
+		t.Errorf("bail: %v", errors.Get("avatar"))
+	}
+}
+
+func TestFileRulesKeepTheHeader(t *testing.T) {
+	form := newTestMultipartForm(t, nil, map[string][]testFile{"doc": {{"a.txt", []byte("hello")}}})
+
+	data, errors := form.ValidateAll(Map{"doc": "maxBytes:10|mimes:txt"}, nil)
+
+	if errors != nil {
+		t.Fatal(errors)
+	}
+
+	file, isFile := data["doc"].(FormFile)
+
+	if !isFile || file.File != nil || file.FileHeader == nil || file.FileHeader.Filename != "a.txt" {
+		t.Errorf("data = %#v", data["doc"])
+	}
+}
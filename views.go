@@ -0,0 +1,270 @@
+/*
+ * This file contains the view rendering, based on html/template.
+ */
+package govel
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// directory of the views, it can be changed with "views.dir" in the .yaml file.
+	viewsDir = "views"
+
+	// extension of the view files, it can be changed with "views.extension" in the .yaml file.
+	viewsExtension = ".html"
+
+	// the compiled views, only used when debug mode is off.
+	viewsCache   = make(map[string]*compiledView)
+	viewsCacheMu sync.RWMutex
+
+	// functions added with ViewFuncs.
+	userViewFuncs = template.FuncMap{}
+
+	// matches {{ extends "layouts/app" }}
+	extendsRegex = regexp.MustCompile(`{{-?\s*extends\s+"([^"]+)"\s*-?}}`)
+)
+
+// partialsDir is the directory inside the views directory whose files are available in every view.
+const partialsDir = "partials"
+
+type compiledView struct {
+	template *template.Template
+
+	// the name of the template that is executed, the view itself or its outermost layout.
+	entry string
+}
+
+// ViewFuncs adds functions to the views.
+//
+// It must be called before the first view is rendered.
+func ViewFuncs(funcs template.FuncMap) {
+	for name, function := range funcs {
+		userViewFuncs[name] = function
+	}
+}
+
+// View renders a view from the views directory with data.
+//
+// name is the path of the file without the extension, e.g. "users/show".
+// A view can extend a layout by starting with {{extends "layouts/app"}} and defining the blocks of the layout.
+// The files in the "partials" directory can be included in any view with {{template "partials/name" .}}.
+//
+// Views are cached unless "debug: true" is set in the .yaml file, in which case they are reloaded on every render.
+func (c *Context) View(statusCode int, name string, data interface{}) error {
+	view, err := loadView(name)
+
+	if err != nil {
+		return err
+	}
+
+	// the functions depend on the request, so they are set on a copy of the cached template
+	tmpl, err := view.template.Clone()
+
+	if err != nil {
+		return err
+	}
+
+	tmpl.Funcs(c.viewFuncs())
+
+	// render into a separate buffer so an error does not leave a partial view
+	var buf bytes.Buffer
+
+	err = tmpl.ExecuteTemplate(&buf, view.entry, data)
+
+	if err != nil {
+		return err
+	}
+
+	c.ContentType("text/html; charset=utf-8")
+	c.Status(statusCode)
+
+	c.Buf.Write(buf.Bytes())
+
+	return nil
+}
+
+// loadView returns the compiled view from the cache or compiles it.
+func loadView(name string) (*compiledView, error) {
+	if !debugMode {
+		viewsCacheMu.RLock()
+		view, exists := viewsCache[name]
+		viewsCacheMu.RUnlock()
+
+		if exists {
+			return view, nil
+		}
+	}
+
+	view, err := compileView(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !debugMode {
+		viewsCacheMu.Lock()
+		viewsCache[name] = view
+		viewsCacheMu.Unlock()
+	}
+
+	return view, nil
+}
+
+// compileView parses the partials, the layouts of the view (outermost first) and the view.
+func compileView(name string) (*compiledView, error) {
+	tmpl := template.New("view:" + name).Funcs((&Context{}).viewFuncs())
+
+	// the view and its layouts, the view first
+	var chain []string
+	var contents []string
+
+	for current := name; current != ""; {
+		for _, previous := range chain {
+			if previous == current {
+				return nil, fmt.Errorf("govel: view %s extends itself", current)
+			}
+		}
+
+		content, err := os.ReadFile(viewPath(current))
+
+		if err != nil {
+			return nil, fmt.Errorf("govel: cannot read view %s: %w", current, err)
+		}
+
+		chain = append(chain, current)
+		contents = append(contents, string(content))
+
+		current = ""
+
+		if match := extendsRegex.FindStringSubmatch(string(content)); match != nil {
+			current = match[1]
+		}
+	}
+
+	err := parsePartials(tmpl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the blocks of the layouts are defaults, so they are parsed before the blocks that override them
+	for i := len(chain) - 1; i >= 0; i-- {
+		_, err := tmpl.New(chain[i]).Parse(contents[i])
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &compiledView{template: tmpl, entry: chain[len(chain)-1]}, nil
+}
+
+// parsePartials adds every file of the partials directory to the template.
+func parsePartials(tmpl *template.Template) error {
+	root := filepath.Join(viewsDir, partialsDir)
+
+	if _, err := os.Stat(root); err != nil {
+		return nil
+	}
+
+	return filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(file) != viewsExtension {
+			return err
+		}
+
+		content, err := os.ReadFile(file)
+
+		if err != nil {
+			return err
+		}
+
+		relative, _ := filepath.Rel(viewsDir, file)
+		name := strings.TrimSuffix(filepath.ToSlash(relative), viewsExtension)
+
+		_, err = tmpl.New(name).Parse(string(content))
+
+		return err
+	})
+}
+
+// viewPath returns the file of a view.
+func viewPath(name string) string {
+	return filepath.Join(viewsDir, filepath.FromSlash(path.Clean("/"+name))+viewsExtension)
+}
+
+// viewFuncs returns the functions available in the views.
+func (c *Context) viewFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		// extends is handled when the view is compiled
+		"extends": func(layout string) string {
+			return ""
+		},
+
+		// route "users.show" "id" "1"
+		"route": func(name string, params ...string) string {
+			data := make(SMap)
+
+			for i := 0; i+1 < len(params); i += 2 {
+				data[params[i]] = params[i+1]
+			}
+
+			return Route(name, data)
+		},
+
+		"csrf_token": func() string {
+			return c.CSRFToken()
+		},
+
+		"csrf_field": func() template.HTML {
+			return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`, CSRFFieldName, template.HTMLEscapeString(c.CSRFToken())))
+		},
+
+		"old": func(key string, fallback ...string) string {
+			return c.Old(key, fallback...)
+		},
+
+		// errors returns all the errors, or the error of a field if a key is given
+		"errors": func(key ...string) interface{} {
+			if len(key) > 0 {
+				return c.Errors()[key[0]]
+			}
+
+			return c.Errors()
+		},
+
+		// t "cart.items" "count" 3
+		"t": func(key string, params ...interface{}) string {
+			args := make(Map)
+
+			for i := 0; i+1 < len(params); i += 2 {
+				args[fmt.Sprint(params[i])] = params[i+1]
+			}
+
+			return c.T(key, args)
+		},
+
+		"flash": func(key string) interface{} {
+			return c.Flash(key)
+		},
+
+		"asset": func(file string) string {
+			return strings.TrimSuffix(staticPath, "/") + "/" + strings.TrimPrefix(file, "/")
+		},
+	}
+
+	for name, function := range userViewFuncs {
+		funcs[name] = function
+	}
+
+	return funcs
+}
@@ -0,0 +1,493 @@
+/*
+ * This file contains the WebSocket routes, the connection wrapper and the hub.
+ */
+package govel
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// WebSocketUpgrader upgrades the connections of the WebSocket routes.
+	//
+	// Set its CheckOrigin field to accept cross-origin connections.
+	WebSocketUpgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024}
+
+	// WebSocketPingInterval is the time between the pings sent to the client.
+	WebSocketPingInterval = 50 * time.Second
+
+	// WebSocketPongWait is the time to wait for any message (pongs included) before closing the connection.
+	//
+	// It must be greater than WebSocketPingInterval.
+	WebSocketPongWait = 60 * time.Second
+
+	// WebSocketWriteWait is the time allowed to write a message.
+	WebSocketWriteWait = 10 * time.Second
+
+	// WebSocketMaxMessageSize is the maximum size in bytes of a message sent by the client.
+	WebSocketMaxMessageSize int64 = 1 << 20
+
+	// WebSocketSendQueueSize is the number of messages that can be waiting to be sent to a client.
+	//
+	// Clients that are too slow to keep up are disconnected.
+	WebSocketSendQueueSize = 256
+
+	// ErrWebSocketClosed is returned when sending to a closed connection.
+	ErrWebSocketClosed = errors.New("websocket: connection closed")
+
+	// ErrWebSocketQueueFull is returned when the send queue of a connection is full, the connection is closed.
+	ErrWebSocketQueueFull = errors.New("websocket: send queue full")
+)
+
+type webSocketMessage struct {
+	messageType int
+	data        []byte
+}
+
+// WebSocketConn is an open WebSocket connection.
+//
+// Messages are sent through a queue, so the Send methods can be called from any goroutine.
+type WebSocketConn struct {
+	conn *websocket.Conn
+
+	c *Context
+
+	send chan webSocketMessage
+
+	done chan struct{}
+
+	writerDone chan struct{}
+
+	closeOnce sync.Once
+
+	closeCode int
+
+	closeText string
+
+	mu sync.Mutex
+
+	// set by CloseWith under mu, OnClose calls the functions registered after it at once.
+	closed bool
+
+	onClose []func()
+}
+
+// WebSocket creates a route that upgrades the connection to a WebSocket and calls handler with it.
+//
+// The global and route middlewares run before the upgrade, so they can reject the connection.
+// The connection is closed when handler returns.
+func WebSocket(path string, handler func(conn *WebSocketConn)) *routeModel {
+
+	m := routeModel{unique_id: time.Now().String(), path: path, action: webSocketAction(handler), actionName: functionName(handler), method: "GET"}
+	m.update()
+
+	return &m
+}
+
+// webSocketAction upgrades the connection and calls the handler.
+func webSocketAction(handler func(conn *WebSocketConn)) routeFunction {
+	return func(c *Context) error {
+		if !websocket.IsWebSocketUpgrade(c.Request) {
+			return NewHTTPError(http.StatusUpgradeRequired, "WebSocket upgrade required")
+		}
+
+		// the upgrade response only contains the headers passed to Upgrade,
+		// so the sessions and the headers set by the middlewares must be prepared first
+		c.prepareHeaders()
+
+		conn, err := WebSocketUpgrader.Upgrade(c.ResponseWriter, c.Request, c.ResponseWriter.Header())
+
+		// Upgrade already replied to the client
+		c.committed = true
+
+		if err != nil {
+			return nil
+		}
+
+		ws := &WebSocketConn{
+			conn:       conn,
+			c:          c,
+			send:       make(chan webSocketMessage, WebSocketSendQueueSize),
+			done:       make(chan struct{}),
+			writerDone: make(chan struct{}),
+			closeCode:  websocket.CloseNormalClosure,
+		}
+
+		conn.SetReadLimit(WebSocketMaxMessageSize)
+		conn.SetReadDeadline(time.Now().Add(WebSocketPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(WebSocketPongWait))
+		})
+
+		go ws.writer()
+
+		defer func() {
+			ws.Close()
+
+			<-ws.writerDone
+		}()
+
+		handler(ws)
+
+		return nil
+	}
+}
+
+// writer sends the queued messages and the pings, it is the only goroutine that writes to the connection.
+func (ws *WebSocketConn) writer() {
+	ticker := time.NewTicker(WebSocketPingInterval)
+
+	defer func() {
+		ticker.Stop()
+		ws.conn.Close()
+		close(ws.writerDone)
+	}()
+
+	for {
+		select {
+		case message := <-ws.send:
+			ws.conn.SetWriteDeadline(time.Now().Add(WebSocketWriteWait))
+
+			if err := ws.conn.WriteMessage(message.messageType, message.data); err != nil {
+				ws.Close()
+				return
+			}
+
+		case <-ticker.C:
+			ws.conn.SetWriteDeadline(time.Now().Add(WebSocketWriteWait))
+
+			if err := ws.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				ws.Close()
+				return
+			}
+
+		case <-ws.done:
+			ws.flush()
+
+			message := websocket.FormatCloseMessage(ws.closeCode, ws.closeText)
+			ws.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(WebSocketWriteWait))
+
+			return
+		}
+	}
+}
+
+// flush sends the messages that were queued before the connection was closed.
+func (ws *WebSocketConn) flush() {
+	for {
+		select {
+		case message := <-ws.send:
+			ws.conn.SetWriteDeadline(time.Now().Add(WebSocketWriteWait))
+
+			if err := ws.conn.WriteMessage(message.messageType, message.data); err != nil {
+				return
+			}
+
+		default:
+			return
+		}
+	}
+}
+
+// Context returns the Context of the upgrade request.
+func (ws *WebSocketConn) Context() *Context {
+	return ws.c
+}
+
+// Conn returns the underlying gorilla/websocket connection.
+//
+// Do not write to it directly, use the Send methods instead.
+func (ws *WebSocketConn) Conn() *websocket.Conn {
+	return ws.conn
+}
+
+// ReadMessage reads the next message.
+//
+// messageType is websocket.TextMessage or websocket.BinaryMessage.
+// An error is returned when the connection is closed.
+func (ws *WebSocketConn) ReadMessage() (messageType int, data []byte, err error) {
+	messageType, data, err = ws.conn.ReadMessage()
+
+	if err != nil {
+		ws.Close()
+	}
+
+	return messageType, data, err
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v.
+func (ws *WebSocketConn) ReadJSON(v interface{}) error {
+	_, data, err := ws.ReadMessage()
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Send queues a text message.
+func (ws *WebSocketConn) Send(data []byte) error {
+	return ws.queue(webSocketMessage{messageType: websocket.TextMessage, data: data})
+}
+
+// SendText queues a text message.
+func (ws *WebSocketConn) SendText(text string) error {
+	return ws.Send([]byte(text))
+}
+
+// SendBinary queues a binary message.
+func (ws *WebSocketConn) SendBinary(data []byte) error {
+	return ws.queue(webSocketMessage{messageType: websocket.BinaryMessage, data: data})
+}
+
+// SendJSON encodes v as JSON and queues it as a text message.
+func (ws *WebSocketConn) SendJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	return ws.Send(data)
+}
+
+func (ws *WebSocketConn) queue(message webSocketMessage) error {
+	select {
+	case <-ws.done:
+		return ErrWebSocketClosed
+
+	default:
+	}
+
+	select {
+	case ws.send <- message:
+		return nil
+
+	default:
+		ws.Close()
+
+		return ErrWebSocketQueueFull
+	}
+}
+
+// Done returns a channel that is closed when the connection is closed.
+func (ws *WebSocketConn) Done() <-chan struct{} {
+	return ws.done
+}
+
+// OnClose registers a function that is called once the connection is closed.
+//
+// If the connection is already closed the function is called immediately.
+func (ws *WebSocketConn) OnClose(function func()) {
+	ws.mu.Lock()
+
+	if ws.closed {
+		ws.mu.Unlock()
+
+		function()
+
+		return
+	}
+
+	ws.onClose = append(ws.onClose, function)
+
+	ws.mu.Unlock()
+}
+
+// Close closes the connection with a normal closure.
+func (ws *WebSocketConn) Close() {
+	ws.CloseWith(websocket.CloseNormalClosure, "")
+}
+
+// CloseWith closes the connection with a close code and a reason.
+//
+// The messages already queued are sent before the close message.
+func (ws *WebSocketConn) CloseWith(code int, reason string) {
+	ws.closeOnce.Do(func() {
+		ws.closeCode = code
+		ws.closeText = reason
+
+		close(ws.done)
+
+		ws.mu.Lock()
+		ws.closed = true
+		callbacks := ws.onClose
+		ws.onClose = nil
+		ws.mu.Unlock()
+
+		for _, callback := range callbacks {
+			callback()
+		}
+	})
+}
+
+/*
+ * Hub
+ */
+
+// Hub keeps track of WebSocket connections and groups them in rooms.
+//
+// Closed connections are removed automatically.
+type Hub struct {
+	mu sync.RWMutex
+
+	conns map[*WebSocketConn]map[string]struct{}
+
+	rooms map[string]map[*WebSocketConn]struct{}
+}
+
+// NewHub creates a Hub.
+func NewHub() *Hub {
+	return &Hub{
+		conns: make(map[*WebSocketConn]map[string]struct{}),
+		rooms: make(map[string]map[*WebSocketConn]struct{}),
+	}
+}
+
+// Register adds a connection to the hub.
+func (h *Hub) Register(conn *WebSocketConn) {
+	h.mu.Lock()
+
+	_, registered := h.conns[conn]
+
+	if !registered {
+		h.conns[conn] = make(map[string]struct{})
+	}
+
+	h.mu.Unlock()
+
+	if !registered {
+		conn.OnClose(func() {
+			h.Unregister(conn)
+		})
+	}
+}
+
+// Unregister removes a connection from the hub and from all its rooms.
+func (h *Hub) Unregister(conn *WebSocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for room := range h.conns[conn] {
+		h.leave(conn, room)
+	}
+
+	delete(h.conns, conn)
+}
+
+// Join adds a connection to a room, the connection is registered if needed.
+func (h *Hub) Join(conn *WebSocketConn, room string) {
+	h.Register(conn)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// the connection may have been closed in the meantime
+	if _, registered := h.conns[conn]; !registered {
+		return
+	}
+
+	if h.rooms[room] == nil {
+		h.rooms[room] = make(map[*WebSocketConn]struct{})
+	}
+
+	h.rooms[room][conn] = struct{}{}
+	h.conns[conn][room] = struct{}{}
+}
+
+// Leave removes a connection from a room.
+func (h *Hub) Leave(conn *WebSocketConn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.leave(conn, room)
+}
+
+func (h *Hub) leave(conn *WebSocketConn, room string) {
+	delete(h.rooms[room], conn)
+	delete(h.conns[conn], room)
+
+	if len(h.rooms[room]) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+// Broadcast sends a text message to every connection of the hub.
+func (h *Hub) Broadcast(data []byte) {
+	h.mu.RLock()
+	conns := make([]*WebSocketConn, 0, len(h.conns))
+
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+
+	h.mu.RUnlock()
+
+	h.send(conns, data)
+}
+
+// BroadcastTo sends a text message to every connection of a room.
+func (h *Hub) BroadcastTo(room string, data []byte) {
+	h.mu.RLock()
+	conns := make([]*WebSocketConn, 0, len(h.rooms[room]))
+
+	for conn := range h.rooms[room] {
+		conns = append(conns, conn)
+	}
+
+	h.mu.RUnlock()
+
+	h.send(conns, data)
+}
+
+// BroadcastJSON encodes v as JSON and sends it to every connection of the hub.
+func (h *Hub) BroadcastJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	h.Broadcast(data)
+
+	return nil
+}
+
+// BroadcastJSONTo encodes v as JSON and sends it to every connection of a room.
+func (h *Hub) BroadcastJSONTo(room string, v interface{}) error {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	h.BroadcastTo(room, data)
+
+	return nil
+}
+
+// send queues the message outside of the lock, a full queue closes the connection and unregisters it.
+func (h *Hub) send(conns []*WebSocketConn, data []byte) {
+	for _, conn := range conns {
+		conn.Send(data)
+	}
+}
+
+// Count returns the number of connections in a room, or in the hub if room is empty.
+func (h *Hub) Count(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if room == "" {
+		return len(h.conns)
+	}
+
+	return len(h.rooms[room])
+}